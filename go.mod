@@ -3,6 +3,7 @@ module github.com/danprince/sietch
 go 1.18
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/adrg/frontmatter v0.2.0
 	github.com/alecthomas/chroma v0.10.0
 	github.com/evanw/esbuild v0.14.51
@@ -12,13 +13,12 @@ require (
 	github.com/tdewolff/minify/v2 v2.12.0
 	github.com/yuin/goldmark v1.4.13
 	golang.org/x/sync v0.0.0-20220722155255-886fb9371eb4
+	gopkg.in/yaml.v2 v2.3.0
 	rogchap.com/v8go v0.7.0
 )
 
 require (
-	github.com/BurntSushi/toml v0.3.1 // indirect
 	github.com/dlclark/regexp2 v1.4.0 // indirect
 	github.com/tdewolff/parse/v2 v2.6.2 // indirect
 	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
-	gopkg.in/yaml.v2 v2.3.0 // indirect
 )
@@ -0,0 +1,184 @@
+package builder
+
+import (
+	"path"
+	"time"
+)
+
+// PageGroup is a set of pages that share a value, such as the year or the
+// month that they were published in.
+type PageGroup struct {
+	Key   string
+	Date  time.Time
+	Pages []*Page
+	page  *Page
+}
+
+// The url of the group's archive page, or an empty string if it doesn't have
+// one.
+func (g *PageGroup) Url() string {
+	if g.page == nil {
+		return ""
+	}
+	return g.page.Url
+}
+
+// The directory that archive pages are generated in.
+const archiveDir = "/archive"
+
+// The default contents for a year's archive page.
+const defaultYearTemplate = `{{ range groupByMonth .Archive.Pages -}}
+## [{{ formatDate "January" .Date }}]({{ .Url }})
+
+{{ range .Pages -}}
+- [{{ .Data.title }}]({{ .Url }})
+{{ end }}
+{{ end }}`
+
+// The default contents for a month's archive page.
+const defaultMonthTemplate = `{{ range .Archive.Pages -}}
+- [{{ .Data.title }}]({{ .Url }})
+{{ end }}`
+
+// Groups pages by a key. Groups are in the order that their first page
+// appears, so the pages should already be sorted (e.g. with orderByDate).
+// Pages can be in more than one group if key returns more than one value,
+// and aren't in any group if it returns none.
+func groupPages(pages []*Page, key func(page *Page) []string) []*PageGroup {
+	groups := []*PageGroup{}
+	byKey := map[string]*PageGroup{}
+
+	for _, page := range pages {
+		for _, k := range key(page) {
+			group, ok := byKey[k]
+
+			if !ok {
+				group = &PageGroup{Key: k}
+				byKey[k] = group
+				groups = append(groups, group)
+			}
+
+			// Avoid listing pages twice if they repeat a value
+			if n := len(group.Pages); n == 0 || group.Pages[n-1] != page {
+				group.Pages = append(group.Pages, page)
+			}
+		}
+	}
+
+	return groups
+}
+
+// Groups pages by the year of their date (e.g. "2022"), in the site's
+// timezone. Pages without dates are left out.
+func (b *Builder) groupByYear(pages []*Page) []*PageGroup {
+	groups := groupPages(pages, func(page *Page) []string {
+		if page.Date.IsZero() {
+			return nil
+		}
+		return []string{page.Date.In(b.config.location).Format("2006")}
+	})
+
+	for _, group := range groups {
+		date := group.Pages[0].Date.In(b.config.location)
+		group.Date = time.Date(date.Year(), 1, 1, 0, 0, 0, 0, b.config.location)
+		group.page = b.archivePage(group)
+	}
+
+	return groups
+}
+
+// Groups pages by the year and month of their date (e.g. "2022/03"), in the
+// site's timezone. Pages without dates are left out.
+func (b *Builder) groupByMonth(pages []*Page) []*PageGroup {
+	groups := groupPages(pages, func(page *Page) []string {
+		if page.Date.IsZero() {
+			return nil
+		}
+		return []string{page.Date.In(b.config.location).Format("2006/01")}
+	})
+
+	for _, group := range groups {
+		date := group.Pages[0].Date.In(b.config.location)
+		group.Date = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, b.config.location)
+		group.page = b.archivePage(group)
+	}
+
+	return groups
+}
+
+// Creates a page for each year (e.g. /archive/2022/) and each month (e.g.
+// /archive/2022/03/) that the pages in the config's archive directory were
+// published in. These pages can be customised with _year.md and _month.md
+// files in the archive directory.
+func (b *Builder) collectArchives() error {
+	if b.config.Archive == "" {
+		return nil
+	}
+
+	var pages []*Page
+
+	for _, page := range b.pages {
+		if b.isArchived(page) {
+			pages = append(pages, page)
+		}
+	}
+
+	sortByDateDesc(pages)
+
+	yearTemplateFile := path.Join(b.PagesDir, archiveDir, "_year.md")
+	monthTemplateFile := path.Join(b.PagesDir, archiveDir, "_month.md")
+
+	for _, group := range b.groupByYear(pages) {
+		page, err := b.addGeneratedPage(path.Join(archiveDir, group.Key, "index.md"), yearTemplateFile, defaultYearTemplate, group.Key)
+		if err != nil {
+			return err
+		}
+
+		page.Archive = group
+		group.page = page
+		b.archives[group.Key] = page
+	}
+
+	for _, group := range b.groupByMonth(pages) {
+		title := b.formatDate(b.defaultLang(), "January 2006", group.Date)
+		page, err := b.addGeneratedPage(path.Join(archiveDir, group.Key, "index.md"), monthTemplateFile, defaultMonthTemplate, title)
+		if err != nil {
+			return err
+		}
+
+		page.Archive = group
+		group.page = page
+		b.archives[group.Key] = page
+	}
+
+	return nil
+}
+
+// Whether the page is listed in the archive pages.
+func (b *Builder) isArchived(page *Page) bool {
+	if b.config.Archive == "" || !page.isListable() || page.isPlain() || page.Date.IsZero() {
+		return false
+	}
+
+	_, ok := closestDir(page, []string{b.config.Archive})
+	return ok
+}
+
+// Finds the archive page for a group of pages. Groups only link to archive
+// pages that list all of their pages.
+func (b *Builder) archivePage(group *PageGroup) *Page {
+	for _, page := range group.Pages {
+		if !b.isArchived(page) {
+			return nil
+		}
+	}
+	return b.archives[group.Key]
+}
+
+// Groups pages by a front matter key. Pages with a list of values are in a
+// group for each value.
+func groupByKey(key string, pages []*Page) []*PageGroup {
+	return groupPages(pages, func(page *Page) []string {
+		return stringValues(page.Data[key])
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"text/template"
@@ -25,6 +26,7 @@ import (
 	minhtml "github.com/tdewolff/minify/v2/html"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer/html"
 	"golang.org/x/sync/errgroup"
 )
@@ -57,11 +59,28 @@ type Builder struct {
 	OutDir       string
 	PublicDir    string
 	Mode         Mode
+	Future       bool
 	template     *template.Template
 	templateFile string
+	layoutsDir   string
+	layouts      map[string]*layout
+	layoutsMu    sync.Mutex
+	partialsDir  string
+	partials     *template.Template
+	partialFiles map[string]string
+	partialSrcs  map[string]string
+	defaults     map[string]map[string]defaultValue
+	defaultsMu   sync.Mutex
+	dataDir      string
+	i18nDir      string
+	i18n         map[string]map[string]string
+	site         *Site
 	config       Config
 	configFile   string
 	pages        []*Page
+	aliases      map[string]*Page
+	taxonomies   map[string]taxonomy
+	archives     map[string]*Page
 	assets       map[string]string
 	assetsMu     sync.Mutex
 	index        map[string][]*Page
@@ -70,22 +89,59 @@ type Builder struct {
 	minifier     *minify.M
 	fingerprint  bool
 	minify       bool
+	built        bool
 }
 
-// Page is a markdown file in the site.
+// Page is a file in the pages dir that's rendered with templates (a markdown,
+// html or .tmpl file), or a page that was generated from one, such as an
+// archive, term or pagination page.
 type Page struct {
-	id               string
-	Path             string
-	Dir              string
-	Url              string
-	Data             map[string]any
-	Date             time.Time
-	Contents         string
+	id           string
+	Path         string
+	Dir          string
+	Url          string
+	Data         map[string]any
+	Date         time.Time
+	Updated      time.Time
+	Expires      time.Time
+	Draft        bool
+	Lang         string
+	Translations []*Page
+	Contents     string
+	Summary      string
+	WordCount    int
+	ReadingTime  int
+	Site         *Site
+	Paginator    *Paginator
+	Term         *Term
+	Archive      *PageGroup
+	Item         any
+	Toc          []*mdext.Heading
+	Parent       *Page
+	Children     []*Page
+	Ancestors    []*Page
+	Prev         *Page
+	Next         *Page
+
+	body             string
+	src              string
 	template         *template.Template
+	layout           *layout
 	inputPath        string
 	outputPath       string
 	contentStartLine int
 	islands          []*islands.Island
+	brokenLinks      []error
+	deps             map[string]bool
+	urls             map[string]bool
+	defaulted        map[string]string
+	listsPages       bool
+	key              string
+	langDir          string
+	source           *Page
+	subpath          string
+	virtual          bool
+	generated        bool
 }
 
 // Creates a new island and adds it to the page.
@@ -103,6 +159,11 @@ func (p *Page) addIsland(entryPoint string, props islands.Props) *islands.Island
 	return island
 }
 
+// Records that the page needs to be rebuilt whenever file changes.
+func (p *Page) addDep(file string) {
+	p.deps[path.Clean(file)] = true
+}
+
 // Creates a new builder with the default settings.
 func New(dir string, mode Mode) *Builder {
 	min := minify.New()
@@ -117,9 +178,19 @@ func New(dir string, mode Mode) *Builder {
 		OutDir:       path.Join(dir, "_site"),
 		AssetsDir:    path.Join(dir, "_site/_assets"),
 		templateFile: path.Join(dir, "_template.html"),
+		layoutsDir:   path.Join(dir, "_layouts"),
+		layouts:      map[string]*layout{},
+		defaults:     map[string]map[string]defaultValue{},
+		partialsDir:  path.Join(dir, "_partials"),
+		dataDir:      path.Join(dir, "_data"),
+		i18nDir:      path.Join(dir, "_i18n"),
+		site:         &Site{},
 		configFile:   path.Join(dir, ".sietch.json"),
 		config:       defaultConfig,
 		pages:        []*Page{},
+		aliases:      map[string]*Page{},
+		taxonomies:   map[string]taxonomy{},
+		archives:     map[string]*Page{},
 		index:        map[string][]*Page{},
 		assets:       map[string]string{},
 		assetsMu:     sync.Mutex{},
@@ -134,8 +205,14 @@ func New(dir string, mode Mode) *Builder {
 func (b *Builder) Reset() {
 	b.config = defaultConfig
 	b.pages = []*Page{}
+	b.site = &Site{}
+	b.taxonomies = map[string]taxonomy{}
+	b.archives = map[string]*Page{}
 	b.index = map[string][]*Page{}
 	b.assets = map[string]string{}
+	b.built = false
+	b.clearLayouts()
+	b.clearDefaults()
 }
 
 // Builds the site.
@@ -157,6 +234,21 @@ func (b *Builder) Build() error {
 		return err
 	}
 
+	err = b.readPartials()
+	if err != nil {
+		return err
+	}
+
+	err = b.readData()
+	if err != nil {
+		return err
+	}
+
+	err = b.readI18n()
+	if err != nil {
+		return err
+	}
+
 	err = b.findAssets()
 	if err != nil {
 		return err
@@ -167,45 +259,101 @@ func (b *Builder) Build() error {
 		return err
 	}
 
-	err = b.readPages()
+	err = b.readPages(b.pages)
 	if err != nil {
 		return err
 	}
 
-	err = b.buildPages()
+	b.removeUnpublished()
+
+	err = b.generatePages()
 	if err != nil {
 		return err
 	}
 
-	err = b.renderIslands()
+	err = b.collectTaxonomies()
 	if err != nil {
 		return err
 	}
 
-	err = b.bundleIslands()
+	err = b.collectArchives()
 	if err != nil {
 		return err
 	}
 
-	if b.Mode == Development {
-		b.injectDevScripts()
+	err = b.paginatePages()
+	if err != nil {
+		return err
 	}
 
-	if b.minify {
-		err := b.minifyPages()
-		if err != nil {
-			return err
-		}
+	b.collectSitePages()
+	b.linkTranslations()
+
+	err = b.assignUrls()
+	if err != nil {
+		return err
+	}
+
+	b.linkPages()
+
+	err = b.renderPages(b.pages)
+	if err != nil {
+		return err
+	}
+
+	err = b.writeSiteFiles()
+	if err != nil {
+		return err
+	}
+
+	err = b.copyAssets()
+	if err != nil {
+		return err
 	}
 
-	err = b.writeFiles()
+	err = b.checkLinks(b.pages)
 	if err != nil {
 		return err
 	}
 
+	b.built = true
 	return nil
 }
 
+// Takes pages that have been read and turns them into finished HTML files in
+// the output directory.
+func (b *Builder) renderPages(pages []*Page) error {
+	var err error
+
+	err = b.buildPages(pages)
+	if err != nil {
+		return err
+	}
+
+	err = b.renderIslands(pages)
+	if err != nil {
+		return err
+	}
+
+	err = b.bundleIslands(pages)
+	if err != nil {
+		return err
+	}
+
+	if b.Mode == Development {
+		b.injectDevScripts(pages)
+	}
+
+	if b.minify {
+		err := b.minifyPages(pages)
+		if err != nil {
+			return err
+		}
+	}
+
+	return b.writeFiles(pages)
+}
+
 // Read and parse the site's config file
 func (b *Builder) readConfig() error {
 	return b.config.load(b.configFile)
@@ -224,8 +372,9 @@ func (b *Builder) applyConfig() error {
 		goldmark.WithExtensions(
 			extension.GFM,
 			extension.Footnote,
-			mdext.Links,
+			mdext.NewLinks(b.config.PrettyUrls),
 			mdext.HeadingAnchors,
+			mdext.Toc,
 			mdext.NewSyntaxHighlighting(b.config.SyntaxColor),
 		),
 		goldmark.WithRendererOptions(
@@ -233,6 +382,7 @@ func (b *Builder) applyConfig() error {
 		),
 	)
 
+	b.applySiteConfig()
 	return nil
 }
 
@@ -255,12 +405,24 @@ func (b *Builder) templateFuncs(page *Page) template.FuncMap {
 	return template.FuncMap{
 		"url": func(src string) string {
 			file := path.Join(b.PagesDir, page.Dir, src)
+			page.addDep(file)
+
+			// Files that are built into pages (e.g. other .html files) point at
+			// the built page, rather than being copied as they are.
+			if other := b.findPage(file); other != nil {
+				relPath := relativeUrl(page.urlDir(), other.Url)
+				page.urls[relPath] = true
+				return relPath
+			}
+
 			absPath := b.addAsset(file)
-			relPath, _ := filepath.Rel(page.Dir, absPath)
+			relPath, _ := filepath.Rel(page.urlDir(), absPath)
+			page.urls[relPath] = true
 			return relPath
 		},
 		"embed": func(src string) string {
 			file := path.Join(path.Dir(page.inputPath), src)
+			page.addDep(file)
 			contents, err := os.ReadFile(file)
 			if err != nil {
 				panic(err)
@@ -270,17 +432,13 @@ func (b *Builder) templateFuncs(page *Page) template.FuncMap {
 		"page": func(src string) *Page {
 			file := regexp.MustCompile(`/$`).ReplaceAllString(src, "/index.md")
 			file = path.Join(path.Dir(page.inputPath), file)
-
-			for _, p := range b.pages {
-				if p.inputPath == file {
-					return p
-				}
-			}
-
-			return nil
+			page.addDep(file)
+			return b.findPage(file)
 		},
+		"partial": b.partialFunc(page),
 		"index": func() []*Page {
-			return b.index[page.Dir]
+			page.listsPages = true
+			return b.index[page.langDir]
 		},
 		"orderByDate": func(order string, pages []*Page) []*Page {
 			if order != "asc" && order != "desc" {
@@ -297,14 +455,34 @@ func (b *Builder) templateFuncs(page *Page) template.FuncMap {
 			return pages
 		},
 		"pagesWith": func(key string) []*Page {
+			page.listsPages = true
 			var pages []*Page
 			for _, page := range b.pages {
-				if page.Data[key] != nil {
+				if page.Data[key] != nil && page.isListable() {
 					pages = append(pages, page)
 				}
 			}
 			return pages
 		},
+		"terms": func(name string) []*Term {
+			page.listsPages = true
+			tax, ok := b.taxonomies[name]
+			if !ok {
+				panic(fmt.Sprintf(`unknown taxonomy "%s"`, name))
+			}
+			return tax.terms()
+		},
+		"pagesByTerm": func(name string, value string) []*Page {
+			page.listsPages = true
+			tax, ok := b.taxonomies[name]
+			if !ok {
+				panic(fmt.Sprintf(`unknown taxonomy "%s"`, name))
+			}
+			if term, ok := tax[slugify(value)]; ok {
+				return term.Pages
+			}
+			return []*Page{}
+		},
 		"sortBy": func(key string, pages []*Page) []*Page {
 			sort.SliceStable(pages, func(i, j int) bool {
 				a := pages[i].Data[key]
@@ -313,6 +491,9 @@ func (b *Builder) templateFuncs(page *Page) template.FuncMap {
 			})
 			return pages
 		},
+		"groupBy":      groupByKey,
+		"groupByYear":  b.groupByYear,
+		"groupByMonth": b.groupByMonth,
 		"props": func(kvs ...any) islands.Props {
 			if len(kvs)%2 != 0 {
 				panic("unbalanced number of keys/values")
@@ -337,6 +518,7 @@ func (b *Builder) templateFuncs(page *Page) template.FuncMap {
 				// Make the import relative to the pagesDir, so we can use a consistent
 				// resolveDir for all islands when we create the static bundle.
 				entryPoint = "." + path.Join(page.Dir, entryPoint)
+				page.addDep(path.Join(b.PagesDir, entryPoint))
 			}
 
 			var props islands.Props
@@ -365,6 +547,34 @@ func (b *Builder) templateFuncs(page *Page) template.FuncMap {
 			island.ClientOnly = true
 			return island
 		},
+		"i18n": func(key string) (string, error) {
+			return b.translate(page.Lang, key)
+		},
+		"formatDate": func(layout string, date time.Time) string {
+			return b.formatDate(page.Lang, layout, date)
+		},
+		"timeAgo": func(date time.Time) string {
+			return relativeTime(date, b.site.BuildTime)
+		},
+		"toc": func(levels ...int) string {
+			min, max := 1, 6
+			if len(levels) > 2 {
+				panic("toc expects a min and max level")
+			}
+			if len(levels) > 0 {
+				min = levels[0]
+			}
+			if len(levels) > 1 {
+				max = levels[1]
+			}
+			// Page templates run before their headings exist, so leave a marker
+			// that can be replaced once they do. The blank lines keep markdown
+			// from putting the list inside a paragraph.
+			if page.Toc == nil {
+				return fmt.Sprintf("\n\n<!-- sietch:toc %d %d -->\n\n", min, max)
+			}
+			return mdext.RenderToc(page.Toc, min, max)
+		},
 		"defaultStyles": func() string {
 			return defaultTemplateCss
 		},
@@ -382,7 +592,7 @@ func (b *Builder) readTemplate() error {
 		return errors.Wrap("template", err)
 	}
 
-	t, err := template.New("template").Funcs(funcs).Parse(string(contents))
+	t, err := template.New(b.templateFile).Funcs(funcs).Parse(string(contents))
 
 	if err != nil {
 		return errors.TemplateParseError(err, b.templateFile, string(contents), 0)
@@ -399,12 +609,17 @@ var ignorePatterns = []string{
 	`^node_modules$`,
 }
 
-// Recursive walk through the site's pages dir, searching for markdown files
-// and adding them to the builder.
+// Recursive walk through the site's pages dir, searching for markdown, html
+// and template files and adding them to the builder.
 func (b *Builder) findPages() error {
 	err := filepath.WalkDir(b.PagesDir, func(p string, d fs.DirEntry, err error) error {
 		name := d.Name()
 
+		// Files in the public dir are copied as they are.
+		if d.IsDir() && p == b.PublicDir {
+			return filepath.SkipDir
+		}
+
 		for _, re := range ignorePatterns {
 			if ok, _ := regexp.MatchString(re, name); ok {
 				if d.IsDir() {
@@ -416,9 +631,8 @@ func (b *Builder) findPages() error {
 		}
 
 		rel := strings.TrimPrefix(p, b.PagesDir)
-		ext := path.Ext(rel)
 
-		if ext == ".md" {
+		if !d.IsDir() && isPageFile(rel) {
 			b.addPage(rel)
 		}
 
@@ -460,28 +674,26 @@ func (b *Builder) findAssets() error {
 // Adds a page to the builder given a path that is relative to the pagesDir.
 func (b *Builder) addPage(relPath string) {
 	id := shortHash(relPath)
-	name := path.Base(relPath)
-	dir := path.Dir(relPath)
-	out := strings.Replace(relPath, ".md", ".html", 1)
-	url := strings.Replace(out, "index.html", "", 1)
+	lang, key := b.pathLang(relPath)
 	inputPath := path.Join(b.PagesDir, relPath)
-	outputPath := path.Join(b.OutDir, out)
 
 	page := &Page{
-		id:         id,
-		Path:       relPath,
-		Url:        url,
-		Dir:        dir,
-		Data:       map[string]any{},
-		inputPath:  inputPath,
-		outputPath: outputPath,
+		id:        id,
+		Path:      relPath,
+		Dir:       path.Dir(relPath),
+		Data:      map[string]any{},
+		Lang:      lang,
+		Site:      b.site,
+		key:       key,
+		langDir:   path.Join("/", b.langPrefix(lang), path.Dir(key)),
+		inputPath: inputPath,
 	}
 
-	parent := dir
+	parent := page.langDir
 
 	// index.md files are indexed as though they were in the parent directory.
 	// (e.g. /posts/hello-world/index.md would be indexed in /posts).
-	if name == "index.md" {
+	if isIndexFile(key) {
 		parent = path.Dir(parent)
 	}
 
@@ -489,18 +701,21 @@ func (b *Builder) addPage(relPath string) {
 		b.index[parent] = []*Page{}
 	}
 
-	// The root index.md doesn't ever get indexed
-	if name != "index.md" || dir != "/" {
+	// The root index.md (of each language) and plain templates don't ever get
+	// indexed.
+	isRoot := isIndexFile(key) && path.Dir(key) == "/"
+
+	if !isRoot && !page.isPlain() {
 		b.index[parent] = append(b.index[parent], page)
 	}
 
 	b.pages = append(b.pages, page)
 }
 
-// Read all pages in the site concurrently.
-func (b *Builder) readPages() error {
+// Read pages concurrently.
+func (b *Builder) readPages(pages []*Page) error {
 	var g errgroup.Group
-	for _, page := range b.pages {
+	for _, page := range pages {
 		p := page
 		g.Go(func() error {
 			return b.readPage(p)
@@ -517,6 +732,24 @@ func (b *Builder) readPage(page *Page) error {
 		return errors.Wrap("builder", err)
 	}
 
+	return b.parsePage(page, rawContents)
+}
+
+// Parses the page's metadata and template from the raw contents of its file.
+func (b *Builder) parsePage(page *Page, rawContents []byte) error {
+	// Forget anything we learned about the page during a previous build
+	page.Data = map[string]any{}
+	page.Date = time.Time{}
+	page.Updated = time.Time{}
+	page.Expires = time.Time{}
+	page.Draft = false
+	page.islands = nil
+	page.listsPages = false
+	page.Paginator = nil
+	page.deps = map[string]bool{}
+	page.addDep(page.inputPath)
+	page.addDep(b.configFile)
+
 	r := bytes.NewReader(rawContents)
 	contents, err := frontmatter.Parse(r, &page.Data)
 
@@ -524,6 +757,10 @@ func (b *Builder) readPage(page *Page) error {
 		return errors.YamlParseError(err, page.inputPath, string(rawContents))
 	}
 
+	if err := b.applyDefaults(page); err != nil {
+		return err
+	}
+
 	// Figure out number of lines of front matter for line numbers in errors
 	frontMatterLen := len(rawContents) - len(contents)
 	frontMatterBytes := contents[:frontMatterLen]
@@ -531,6 +768,7 @@ func (b *Builder) readPage(page *Page) error {
 
 	// Contents is everything after the front matter
 	page.Contents = string(contents)
+	page.src = page.Contents
 
 	// Parse the page template
 	funcs := b.templateFuncs(page)
@@ -539,67 +777,205 @@ func (b *Builder) readPage(page *Page) error {
 		return errors.TemplateParseError(err, page.inputPath, string(contents), page.contentStartLine)
 	}
 	page.template = tmpl
+	page.listsPages = b.templateListsPages(tmpl)
 
-	// Attempt to parse the date from front matter
-	date := page.Data["date"]
-	if date != nil {
-		if s, ok := date.(string); ok {
-			if t, err := time.Parse(b.config.DateFormat, s); err == nil {
-				page.Date = t
-			}
+	page.layout, err = b.pageLayout(page)
+	if err != nil {
+		return err
+	}
+
+	if page.layout != nil {
+		for _, file := range page.layout.files {
+			page.addDep(file)
 		}
 	}
 
-	return nil
+	if draft, ok := page.Data["draft"].(bool); ok {
+		page.Draft = draft
+	}
+
+	if err := b.readDates(page); err != nil {
+		return err
+	}
+
+	if page.virtual {
+		return nil
+	}
+
+	return b.validateFrontMatter(page)
 }
 
-// Builds all pages concurrently.
-func (b *Builder) buildPages() error {
-	var g errgroup.Group
-	for _, page := range b.pages {
-		p := page
-		g.Go(func() error {
-			return b.buildPage(p)
-		})
+// Whether a page should be included in the current build. Drafts, pages
+// with dates in the future, and pages that have expired are only published
+// during development.
+func (b *Builder) isPublished(page *Page) bool {
+	if b.Mode == Development {
+		return true
 	}
-	return g.Wait()
+
+	now := b.site.BuildTime
+	isFuture := page.Date.After(now) && !b.Future
+	isExpired := !page.Expires.IsZero() && !page.Expires.After(now)
+	return !page.Draft && !isFuture && !isExpired
 }
 
-// Converts the page's markdown into HTML and renders it into the builder's
-// template file.
-func (b *Builder) buildPage(page *Page) error {
-	funcs := b.templateFuncs(page)
-	globalTemplate, err := b.template.Clone()
-	globalTemplate.Funcs(funcs).Parse("")
+// Removes pages that shouldn't be published from the site, so that they don't
+// show up in functions like "index" and "pagesWith" either.
+func (b *Builder) removeUnpublished() {
+	b.pages = b.publishedPages(b.pages)
 
-	if err != nil {
-		return errors.TemplateParseError(err, page.inputPath, page.Contents, page.contentStartLine)
+	for dir, pages := range b.index {
+		b.index[dir] = b.publishedPages(pages)
+	}
+}
+
+func (b *Builder) publishedPages(pages []*Page) []*Page {
+	published := []*Page{}
+	for _, page := range pages {
+		if b.isPublished(page) {
+			published = append(published, page)
+		}
+	}
+	return published
+}
+
+// Builds pages concurrently. Pages that list other pages are built after the
+// rest, so that they can show the other pages' summaries. Every page's body
+// is built before any layouts are rendered.
+//
+// Pages that list other pages can also list each other (e.g. the root index
+// listing posts/index.md), so they're built one at a time, starting from the
+// deepest directories.
+func (b *Builder) buildPages(pages []*Page) error {
+	wasListed := b.site.resetListed()
+
+	var listers, others []*Page
+
+	for _, page := range pages {
+		if page.listsPages {
+			listers = append(listers, page)
+		} else {
+			others = append(others, page)
+		}
+	}
+
+	if err := eachPage(others, b.buildBody); err != nil {
+		return err
+	}
+
+	sort.SliceStable(listers, func(i, j int) bool {
+		return dirDepth(listers[i].langDir) > dirDepth(listers[j].langDir)
+	})
+
+	for _, page := range listers {
+		if err := b.buildBody(page); err != nil {
+			return err
+		}
+	}
+
+	if wasListed {
+		b.site.markListed()
 	}
 
-	var mdbuf, htmlbuf, pagebuf bytes.Buffer
+	return eachPage(pages, b.buildLayout)
+}
+
+var tocMarkerRegex = regexp.MustCompile(`(?:\n\n)?<!-- sietch:toc (\d+) (\d+) -->(?:\n\n)?`)
+
+// Replaces the markers that "toc" leaves when it's called before the page's
+// headings are known. Pages that aren't markdown don't have any headings, so
+// their markers are removed.
+func renderTocMarkers(page *Page, html string) string {
+	return tocMarkerRegex.ReplaceAllStringFunc(html, func(marker string) string {
+		m := tocMarkerRegex.FindStringSubmatch(marker)
+		min, _ := strconv.Atoi(m[1])
+		max, _ := strconv.Atoi(m[2])
+		return mdext.RenderToc(page.Toc, min, max)
+	})
+}
+
+// Renders the page's template and converts the resulting markdown into HTML.
+func (b *Builder) buildBody(page *Page) error {
+	var mdbuf, htmlbuf bytes.Buffer
+	page.Toc = nil
+	page.islands = nil
+	page.urls = map[string]bool{}
 
 	if err := page.template.Execute(&mdbuf, page); err != nil {
-		return errors.TemplateExecError(err, page.inputPath, page.Contents, page.contentStartLine)
+		return errors.TemplateExecError(err, page.inputPath, page.src, page.contentStartLine)
+	}
+
+	pc := parser.NewContext()
+
+	// Other types of pages are already in their final format.
+	if !page.isMarkdown() {
+		page.Contents = renderTocMarkers(page, mdbuf.String())
+		page.body = page.Contents
+		return b.summarize(page, pc)
 	}
 
-	if err := b.markdown.Convert(mdbuf.Bytes(), &htmlbuf); err != nil {
+	// Relative links need to be resolved from the page's source dir, even if
+	// the page is being written somewhere else.
+	base, _ := filepath.Rel(page.urlDir(), page.Dir)
+	mdext.SetLinkBase(pc, base)
+
+	// Except for the ones that "url" returned, which are already relative to
+	// the page's url.
+	mdext.SetPageRelative(pc, func(dest string) bool {
+		return page.urls[dest]
+	})
+
+	// Links to markdown files point at the url of the page that the file
+	// became, which can be anywhere thanks to permalinks and translations.
+	mdext.SetLinkResolver(pc, b.linkResolver(page))
+
+	if err := b.markdown.Convert(mdbuf.Bytes(), &htmlbuf, parser.WithContext(pc)); err != nil {
 		return errors.Wrap("markdown", err)
 	}
 
-	page.Contents = htmlbuf.String()
+	page.Toc = mdext.GetToc(pc)
+	page.Contents = renderTocMarkers(page, htmlbuf.String())
+	page.body = page.Contents
+	return b.summarize(page, pc)
+}
 
-	if err := globalTemplate.Execute(&pagebuf, page); err != nil {
-		return errors.TemplateExecError(err, b.templateFile, "", 0)
+// Renders the page's body into its layout.
+func (b *Builder) buildLayout(page *Page) error {
+	if page.layout == nil {
+		page.Contents = page.body
+		return nil
+	}
+
+	funcs := b.templateFuncs(page)
+	layoutTemplate, err := page.layout.template.Clone()
+
+	if err != nil {
+		return errors.TemplateParseError(err, page.inputPath, page.src, page.contentStartLine)
 	}
 
-	page.Contents = pagebuf.String()
+	layoutTemplate.Funcs(funcs)
+
+	// Blocks that the page defines override the ones from its layout.
+	for _, t := range page.template.Templates() {
+		if t != page.template && t.Tree != nil {
+			layoutTemplate.AddParseTree(t.Name(), t.Tree)
+		}
+	}
+
+	var pagebuf bytes.Buffer
+
+	if err := layoutTemplate.ExecuteTemplate(&pagebuf, page.layout.entry, page); err != nil {
+		return b.layoutExecError(err, page)
+	}
+
+	page.Contents = renderTocMarkers(page, pagebuf.String())
 	return nil
 }
 
-func (b *Builder) staticIslands() []*islands.Island {
+func staticIslands(pages []*Page) []*islands.Island {
 	staticIslands := []*islands.Island{}
 
-	for _, p := range b.pages {
+	for _, p := range pages {
 		for _, i := range p.islands {
 			if !i.ClientOnly {
 				staticIslands = append(staticIslands, i)
@@ -622,9 +998,9 @@ func (p *Page) clientIslands() []*islands.Island {
 	return clientIslands
 }
 
-func (b *Builder) renderIslands() error {
+func (b *Builder) renderIslands(pages []*Page) error {
 	elements, err := islands.Render(islands.RenderOptions{
-		Islands:    b.staticIslands(),
+		Islands:    staticIslands(pages),
 		AssetsDir:  b.AssetsDir,
 		ResolveDir: b.PagesDir,
 		Frameworks: b.frameworks,
@@ -636,7 +1012,7 @@ func (b *Builder) renderIslands() error {
 		return err
 	}
 
-	for _, page := range b.pages {
+	for _, page := range pages {
 		for _, island := range page.islands {
 			if html, ok := elements[island.Id]; ok {
 				page.Contents = strings.Replace(page.Contents, island.Marker(), html, 1)
@@ -647,10 +1023,10 @@ func (b *Builder) renderIslands() error {
 	return nil
 }
 
-func (b *Builder) bundleIslands() error {
+func (b *Builder) bundleIslands(pages []*Page) error {
 	islandsByPage := map[string][]*islands.Island{}
 
-	for _, p := range b.pages {
+	for _, p := range pages {
 		clientIslands := p.clientIslands()
 		if len(clientIslands) > 0 {
 			islandsByPage[p.id] = p.clientIslands()
@@ -672,7 +1048,7 @@ func (b *Builder) bundleIslands() error {
 		return err
 	}
 
-	for _, page := range b.pages {
+	for _, page := range pages {
 		if bundle, ok := bundles[page.id]; ok {
 			var scriptTags strings.Builder
 			var linkTags strings.Builder
@@ -695,18 +1071,26 @@ func (b *Builder) bundleIslands() error {
 	return nil
 }
 
-// Injects livereload scripts into pages.
-func (b *Builder) injectDevScripts() {
+var bodyTagRegex = regexp.MustCompile(`<body(\s[^>]*)?>`)
+
+// Shown at the top of draft pages during development.
+const draftBanner = `<div style="position:sticky;top:0;z-index:9999;padding:4px;text-align:center;font:bold 14px sans-serif;background:#ffd23f;color:#000">Draft</div>`
+
+// Injects livereload scripts and draft banners into pages.
+func (b *Builder) injectDevScripts(pages []*Page) {
 	script := fmt.Sprintf("<script>%s</script>", livereload.JS)
-	for _, page := range b.pages {
+	for _, page := range pages {
+		if loc := bodyTagRegex.FindStringIndex(page.Contents); page.Draft && loc != nil {
+			page.Contents = page.Contents[:loc[1]] + draftBanner + page.Contents[loc[1]:]
+		}
 		page.Contents = strings.Replace(page.Contents, "</body>", script+"</body>", 1)
 	}
 }
 
-// Minifies the contents of all pages concurrently
-func (b *Builder) minifyPages() error {
+// Minifies the contents of pages concurrently
+func (b *Builder) minifyPages(pages []*Page) error {
 	var g errgroup.Group
-	for _, page := range b.pages {
+	for _, page := range pages {
 		p := page
 		g.Go(func() error {
 			return b.minifyPage(p)
@@ -717,20 +1101,22 @@ func (b *Builder) minifyPages() error {
 
 // Minifies the contents of a single page.
 func (b *Builder) minifyPage(p *Page) error {
-	for _, p := range b.pages {
-		html, err := b.minifier.String("text/html", p.Contents)
-		if err != nil {
-			return err
-		}
-		p.Contents = html
+	if !p.isHtml() {
+		return nil
+	}
+
+	html, err := b.minifier.String("text/html", p.Contents)
+	if err != nil {
+		return err
 	}
+	p.Contents = html
 	return nil
 }
 
-// Writes all files in the site into the output directory.
-func (b *Builder) writeFiles() error {
-	for _, page := range b.pages {
-		dir := path.Join(b.OutDir, page.Dir)
+// Writes pages into the output directory.
+func (b *Builder) writeFiles(pages []*Page) error {
+	for _, page := range pages {
+		dir := path.Dir(page.outputPath)
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return err
 		}
@@ -739,6 +1125,11 @@ func (b *Builder) writeFiles() error {
 		}
 	}
 
+	return nil
+}
+
+// Copies all assets into the output directory.
+func (b *Builder) copyAssets() error {
 	for src, url := range b.assets {
 		dst := path.Join(b.OutDir, url)
 		copyFile(src, dst)
@@ -209,3 +209,208 @@ func TestFixtures(t *testing.T) {
 		})
 	}
 }
+
+func TestRebuild(t *testing.T) {
+	cwd, _ := os.Getwd()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"index.md":  "{{ range index }}{{ .Data.title }} {{ .Summary }} {{ end }}",
+		"a.md":      "---\ntitle: A\n---\na",
+		"b.md":      "---\ntitle: B\n---\n{{ embed \"hello.txt\" }}",
+		"hello.txt": "hello",
+
+		"_layouts/post.html": "{{ .Contents }}",
+	}
+
+	write := func(name, contents string) string {
+		file := path.Join(dir, name)
+		os.MkdirAll(path.Dir(file), 0755)
+		if err := os.WriteFile(file, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+
+	for name, contents := range files {
+		write(name, contents)
+	}
+
+	builder := New(dir, Production)
+	builder.minify = false
+	builder.fingerprint = false
+	builder.templateFile = path.Join(cwd, "testdata/template.html")
+
+	if err := builder.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace each output with a marker so we can tell which pages were rebuilt.
+	outputs := []string{"index.html", "a.html", "b.html"}
+
+	markStale := func() {
+		for _, name := range outputs {
+			os.WriteFile(path.Join(builder.OutDir, name), []byte("stale"), 0644)
+		}
+	}
+
+	expectRebuilt := func(step string, rebuilt ...string) {
+		t.Helper()
+		for _, name := range outputs {
+			contents, _ := os.ReadFile(path.Join(builder.OutDir, name))
+			isStale := string(contents) == "stale"
+			shouldRebuild := false
+			for _, r := range rebuilt {
+				shouldRebuild = shouldRebuild || r == name
+			}
+			if shouldRebuild && isStale {
+				t.Errorf("%s: expected %s to be rebuilt", step, name)
+			} else if !shouldRebuild && !isStale {
+				t.Errorf("%s: expected %s not to be rebuilt", step, name)
+			}
+		}
+	}
+
+	rebuild := func(files ...string) {
+		t.Helper()
+		markStale()
+		if err := builder.Rebuild(files); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rebuild(write("hello.txt", "goodbye"))
+	expectRebuilt("embedded file", "b.html")
+
+	// The index shows a's summary, and b can show it through .Prev
+	rebuild(write("a.md", "---\ntitle: A\n---\nchanged"))
+	expectRebuilt("page contents", "a.html", "b.html", "index.html")
+
+	index, _ := os.ReadFile(path.Join(builder.OutDir, "index.html"))
+	if !strings.Contains(string(index), "changed") {
+		t.Errorf("expected index to show the new summary, got %s", index)
+	}
+
+	// b is next to a, so it can show a's title through .Prev
+	rebuild(write("a.md", "---\ntitle: A2\n---\nchanged"))
+	expectRebuilt("page front matter", "a.html", "b.html", "index.html")
+
+	rebuild(write("a.md", "---\ntitle: A2\nlayout: post\n---\nchanged"))
+	expectRebuilt("page layout", "a.html", "b.html", "index.html")
+
+	rebuild(write("_layouts/post.html", "<article>{{ .Contents }}</article>"))
+	expectRebuilt("layout file", "a.html")
+
+	rebuild(write("_defaults.yaml", "author: Me"))
+	expectRebuilt("defaults file", "index.html", "a.html", "b.html")
+
+	rebuild(write("c.md", "---\ntitle: C\n---\nc"))
+	expectRebuilt("new page", "index.html", "a.html", "b.html")
+
+	index, _ = os.ReadFile(path.Join(builder.OutDir, "index.html"))
+	if !strings.Contains(string(index), "C") {
+		t.Errorf("expected index to list the new page, got %s", index)
+	}
+}
+
+// Creates a builder for one of the fixtures that writes to a temporary dir,
+// for tests that need to build it differently from TestFixtures.
+func fixtureBuilder(t *testing.T, name string, mode Mode) *Builder {
+	cwd, _ := os.Getwd()
+	builder := New(path.Join(cwd, "testdata/fixtures", name), mode)
+	builder.OutDir = t.TempDir()
+	return builder
+}
+
+func TestDraftsInDevelopment(t *testing.T) {
+	builder := fixtureBuilder(t, "drafts", Development)
+
+	if err := builder.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(path.Join(builder.OutDir, "draft.html"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(contents), draftBanner) {
+		t.Errorf("expected draft page to have a banner, got %s", contents)
+	}
+}
+
+func TestBrokenLinksInDevelopment(t *testing.T) {
+	builder := fixtureBuilder(t, "links_broken", Development)
+
+	if err := builder.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	warnings := builder.Warnings()
+
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %v", warnings)
+	}
+
+	if !strings.Contains(warnings[0].Error(), "missing.html") {
+		t.Errorf("expected a warning about missing.html, got %s", warnings[0])
+	}
+}
+
+func TestBrokenLinksInProduction(t *testing.T) {
+	builder := fixtureBuilder(t, "links_broken", Production)
+
+	err := builder.Build()
+
+	if err == nil || !strings.Contains(err.Error(), "missing.html") {
+		t.Fatalf("expected an error about missing.html, got %v", err)
+	}
+
+	warnings := builder.Warnings()
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "home.html") {
+		t.Errorf("expected only the other broken link to be a warning, got %v", warnings)
+	}
+}
+
+func TestFuturePages(t *testing.T) {
+	builder := fixtureBuilder(t, "scheduled", Production)
+	builder.Future = true
+
+	if err := builder.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path.Join(builder.OutDir, "future.html")); err != nil {
+		t.Errorf("expected future page to be published, got %s", err)
+	}
+
+	if _, err := os.Stat(path.Join(builder.OutDir, "expired.html")); err == nil {
+		t.Errorf("expected expired page not to be published")
+	}
+}
+
+func TestPartialExecErrorSource(t *testing.T) {
+	dir := t.TempDir()
+	partial := path.Join(dir, "_partials/card.html")
+
+	os.MkdirAll(path.Dir(partial), 0755)
+	os.WriteFile(partial, []byte("<div>\n{{ .Data.title.missing }}\n</div>"), 0644)
+	os.WriteFile(path.Join(dir, "index.md"), []byte("---\ntitle: Home\n---\n{{ partial \"card\" . }}"), 0644)
+
+	builder := New(dir, Production)
+	err := builder.Build()
+
+	if err == nil {
+		t.Fatal("expected the partial to fail")
+	}
+
+	// The excerpt should come from the source that was built, even if the
+	// file has changed since.
+	os.Remove(partial)
+
+	if !strings.Contains(errors.NoColor(err), "2 {{ .Data.title.missing }}") {
+		t.Errorf("expected the error to show the partial's source, got %s", errors.NoColor(err))
+	}
+}
@@ -2,28 +2,47 @@ package builder
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/chroma/styles"
 	"github.com/danprince/sietch/internal/errors"
 )
 
 type Config struct {
+	Title       string
+	BaseUrl     string
+	Params      map[string]any
 	Npm         bool
 	SyntaxColor string
 	DateFormat  string
+	DateFormats []string
+	Timezone    string
 	PagesDir    string
 	ImportMap   map[string]string
+	Permalinks  map[string]string
+	PrettyUrls  bool
+	Taxonomies  []string
+	Archive     string
+	Redirects   string
+	CheckLinks  string
+	Languages   []Language
+	Schemas     map[string]Schema
+	location    *time.Location
 }
 
 var defaultConfig = Config{
 	Npm:         false,
 	SyntaxColor: "algol_nu",
 	DateFormat:  "2006-1-2",
+	location:    time.UTC,
 	PagesDir:    ".",
 	ImportMap:   map[string]string{},
+	PrettyUrls:  false,
 }
 
 func (c *Config) load(file string) error {
@@ -56,6 +75,21 @@ func (c *Config) load(file string) error {
 		}
 	}
 
+	if c.Timezone != "" {
+		location, err := time.LoadLocation(c.Timezone)
+
+		if err != nil {
+			return errors.ConfigError{
+				File:    file,
+				Key:     "Timezone",
+				Value:   c.Timezone,
+				Message: "Expected a name from the IANA Time Zone database (e.g. Europe/London).",
+			}
+		}
+
+		c.location = location
+	}
+
 	if strings.HasPrefix(c.PagesDir, "..") || path.IsAbs(c.PagesDir) || strings.HasPrefix(c.PagesDir, "~") {
 		return errors.ConfigError{
 			File:    file,
@@ -65,5 +99,95 @@ func (c *Config) load(file string) error {
 		}
 	}
 
+	if c.Redirects != "" && !redirectFormats[c.Redirects] {
+		allowed := []string{}
+
+		for format := range redirectFormats {
+			allowed = append(allowed, format)
+		}
+
+		return errors.ConfigError{
+			File:    file,
+			Key:     "Redirects",
+			Value:   c.Redirects,
+			Allowed: allowed,
+		}
+	}
+
+	if c.CheckLinks != "" && !linkCheckModes[c.CheckLinks] {
+		allowed := []string{}
+
+		for mode := range linkCheckModes {
+			allowed = append(allowed, mode)
+		}
+
+		return errors.ConfigError{
+			File:    file,
+			Key:     "CheckLinks",
+			Value:   c.CheckLinks,
+			Allowed: allowed,
+		}
+	}
+
+	codes := map[string]bool{}
+
+	for i, lang := range c.Languages {
+		key := fmt.Sprintf("Languages[%d].Code", i)
+
+		if !languageCodeRegex.MatchString(lang.Code) {
+			return errors.ConfigError{
+				File:    file,
+				Key:     key,
+				Value:   lang.Code,
+				Message: "Language codes can only contain letters, numbers and dashes.",
+			}
+		}
+
+		if codes[lang.Code] {
+			return errors.ConfigError{
+				File:    file,
+				Key:     key,
+				Value:   lang.Code,
+				Message: "Each language can only be listed once.",
+			}
+		}
+
+		codes[lang.Code] = true
+	}
+
+	for dir, schema := range c.Schemas {
+		for name, field := range schema.Fields {
+			if _, ok := schemaTypes[field.Type]; !ok && field.Type != "" {
+				return errors.ConfigError{
+					File:    file,
+					Key:     fmt.Sprintf("Schemas[%s].Fields[%s].Type", dir, name),
+					Value:   field.Type,
+					Allowed: mapKeys(schemaTypes),
+				}
+			}
+		}
+	}
+
+	for dir, pattern := range c.Permalinks {
+		for _, m := range permalinkTokenRegex.FindAllStringSubmatch(pattern, -1) {
+			if _, ok := permalinkTokens[m[1]]; !ok {
+				allowed := []string{}
+
+				for token := range permalinkTokens {
+					allowed = append(allowed, ":"+token)
+				}
+
+				sort.Strings(allowed)
+
+				return errors.ConfigError{
+					File:    file,
+					Key:     fmt.Sprintf("Permalinks[%s]", dir),
+					Value:   pattern,
+					Message: fmt.Sprintf("Unknown token :%s (expected one of %s)", m[1], strings.Join(allowed, ", ")),
+				}
+			}
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,157 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/danprince/sietch/internal/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Parsers for each type of data file, by extension.
+var dataParsers = map[string]func(file string, contents []byte) (any, error){
+	".json": parseJsonData,
+	".yaml": parseYamlData,
+	".yml":  parseYamlData,
+	".toml": parseTomlData,
+	".csv":  parseCsvData,
+}
+
+// Reads every data file in the data dir into the site's data. Files are keyed
+// by their names without extensions, and files in subdirectories are nested
+// under the name of the directory (e.g. _data/releases/v1.json can be found
+// at .Site.Data.releases.v1).
+func (b *Builder) readData() error {
+	b.site.Data = map[string]any{}
+
+	info, err := os.Stat(b.dataDir)
+
+	if os.IsNotExist(err) || (err == nil && !info.IsDir()) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap("data", err)
+	}
+
+	return filepath.WalkDir(b.dataDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		parse, ok := dataParsers[path.Ext(file)]
+
+		if !ok {
+			return nil
+		}
+
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return errors.Wrap("data", err)
+		}
+
+		value, err := parse(file, contents)
+		if err != nil {
+			return err
+		}
+
+		rel, _ := filepath.Rel(b.dataDir, file)
+		keys := strings.Split(strings.TrimSuffix(rel, path.Ext(rel)), "/")
+		data := b.site.Data
+
+		for _, key := range keys[:len(keys)-1] {
+			dir, ok := data[key].(map[string]any)
+
+			if !ok {
+				dir = map[string]any{}
+				data[key] = dir
+			}
+
+			data = dir
+		}
+
+		data[keys[len(keys)-1]] = value
+		return nil
+	})
+}
+
+// Whether file is in the data dir.
+func (b *Builder) isDataFile(file string) bool {
+	return strings.HasPrefix(file, b.dataDir+"/")
+}
+
+func parseJsonData(file string, contents []byte) (any, error) {
+	var value any
+	if err := json.Unmarshal(contents, &value); err != nil {
+		return nil, errors.JsonParseError(err, file, string(contents))
+	}
+	return value, nil
+}
+
+func parseYamlData(file string, contents []byte) (any, error) {
+	var value any
+	if err := yaml.Unmarshal(contents, &value); err != nil {
+		return nil, errors.YamlDataError(err, file, string(contents))
+	}
+	return stringKeys(value), nil
+}
+
+func parseTomlData(file string, contents []byte) (any, error) {
+	value := map[string]any{}
+	if err := toml.Unmarshal(contents, &value); err != nil {
+		return nil, errors.TomlParseError(err, file, string(contents))
+	}
+	return value, nil
+}
+
+// Turns each row of a csv file into a map, using the first row as the keys.
+func parseCsvData(file string, contents []byte) (any, error) {
+	rows, err := csv.NewReader(bytes.NewReader(contents)).ReadAll()
+	if err != nil {
+		return nil, errors.CsvParseError(err, file, string(contents))
+	}
+
+	records := []map[string]string{}
+
+	if len(rows) == 0 {
+		return records, nil
+	}
+
+	header := rows[0]
+
+	for _, row := range rows[1:] {
+		record := map[string]string{}
+		for i, key := range header {
+			record[key] = row[i]
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// YAML decodes maps with interface keys, which makes them awkward to use
+// alongside data from other formats. This converts them to string keys.
+func stringKeys(value any) any {
+	switch v := value.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = stringKeys(val)
+		}
+		return m
+	case []any:
+		for i, val := range v {
+			v[i] = stringKeys(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
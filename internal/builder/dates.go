@@ -0,0 +1,167 @@
+package builder
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The layouts that are accepted as well as the ones from the config. These
+// are the timestamps that YAML allows, and ISO 8601 timestamps with or
+// without a timezone. Fractional seconds are always optional.
+var timestampLayouts = []string{
+	"2006-1-2T15:4:5.999999999Z07:00",
+	"2006-1-2t15:4:5.999999999Z07:00",
+	"2006-1-2T15:4:5.999999999Z0700",
+	"2006-1-2T15:4:5.999999999",
+	"2006-1-2 15:4:5.999999999Z07:00",
+	"2006-1-2 15:4:5.999999999 Z07:00",
+	"2006-1-2 15:4:5.999999999 Z0700",
+	"2006-1-2 15:4:5.999999999",
+	"2006-1-2",
+}
+
+// The names in formatted dates that can be translated with the "date." keys
+// in the i18n files (e.g. "date.January").
+var dateNamesRegex = regexp.MustCompile(`\b(January|February|March|April|May|June|July|August|September|October|November|December|Monday|Tuesday|Wednesday|Thursday|Friday|Saturday|Sunday|Jan|Feb|Mar|Apr|Jun|Jul|Aug|Sep|Oct|Nov|Dec|Mon|Tue|Wed|Thu|Fri|Sat|Sun)\b`)
+
+// Attempts to parse a date from a front matter value. Strings are parsed with
+// the layouts from the config first, then as YAML or ISO 8601 timestamps. Dates without
+// a timezone are in the site's timezone.
+func (b *Builder) parseDate(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.In(b.config.location), true
+	case string:
+		layouts := append([]string{b.config.DateFormat}, b.config.DateFormats...)
+		layouts = append(layouts, timestampLayouts...)
+
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, v, b.config.location); err == nil {
+				return t, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Describes the dates that parseDate accepts, for error messages.
+func (b *Builder) expectedDate() string {
+	formats := append([]string{b.config.DateFormat}, b.config.DateFormats...)
+	return fmt.Sprintf("a date in the format %s, or a timestamp like 2006-01-02T15:04:05Z07:00", strings.Join(formats, " or "))
+}
+
+// Reads the page's dates from its front matter. The "updated" (or "lastmod")
+// key says when the page last changed, and pages that don't have one were
+// last updated on their date. The "expires" key says when the page should
+// stop being published.
+func (b *Builder) readDates(page *Page) error {
+	var err error
+
+	if page.Date, err = b.readDate(page, "date"); err != nil {
+		return err
+	}
+
+	if page.Expires, err = b.readDate(page, "expires"); err != nil {
+		return err
+	}
+
+	page.Updated = page.Date
+
+	for _, key := range []string{"updated", "lastmod"} {
+		t, err := b.readDate(page, key)
+
+		if err != nil {
+			return err
+		}
+
+		if !t.IsZero() {
+			page.Updated = t
+			break
+		}
+	}
+
+	return nil
+}
+
+// Parses a date from the page's front matter. Pages don't need to have the
+// key, but if they do, it has to be a date.
+func (b *Builder) readDate(page *Page, key string) (time.Time, error) {
+	value := page.Data[key]
+
+	if value == nil {
+		return time.Time{}, nil
+	}
+
+	t, ok := b.parseDate(value)
+
+	if !ok {
+		return t, frontMatterError(page, key, fmt.Sprintf("expected %s, got %v", b.expectedDate(), value))
+	}
+
+	return t, nil
+}
+
+// Formats a date in the site's timezone, with the names of months and days
+// translated into lang.
+func (b *Builder) formatDate(lang string, layout string, date time.Time) string {
+	s := date.In(b.config.location).Format(layout)
+
+	if len(b.i18n) == 0 {
+		return s
+	}
+
+	return dateNamesRegex.ReplaceAllStringFunc(s, func(name string) string {
+		if translated, err := b.translate(lang, "date."+name); err == nil {
+			return translated
+		}
+		return name
+	})
+}
+
+// Describes how long before (or after) now a date is, in the largest unit
+// that fits (e.g. "3 days ago" or "in 2 hours").
+func relativeTime(date time.Time, now time.Time) string {
+	d := now.Sub(date)
+	future := d < 0
+
+	if future {
+		d = -d
+	}
+
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+
+	for _, unit := range units {
+		if d < unit.size {
+			continue
+		}
+
+		n := int(math.Floor(float64(d) / float64(unit.size)))
+		s := fmt.Sprintf("%d %s", n, unit.name)
+
+		if n != 1 {
+			s += "s"
+		}
+
+		if future {
+			return "in " + s
+		}
+
+		return s + " ago"
+	}
+
+	return "just now"
+}
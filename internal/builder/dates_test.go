@@ -0,0 +1,69 @@
+package builder
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2022, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := map[time.Duration]string{
+		0:                    "just now",
+		30 * time.Second:     "just now",
+		time.Minute:          "1 minute ago",
+		5 * time.Minute:      "5 minutes ago",
+		3 * time.Hour:        "3 hours ago",
+		49 * time.Hour:       "2 days ago",
+		15 * 24 * time.Hour:  "2 weeks ago",
+		65 * 24 * time.Hour:  "2 months ago",
+		400 * 24 * time.Hour: "1 year ago",
+		-2 * time.Hour:       "in 2 hours",
+	}
+
+	for ago, expected := range tests {
+		actual := relativeTime(now.Add(-ago), now)
+		if actual != expected {
+			t.Errorf("expected %s to be %q, got %q", ago, expected, actual)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	b := New(".", Production)
+	b.config.DateFormats = []string{"02/01/2006"}
+	b.config.location = berlin
+
+	tests := map[any]time.Time{
+		"2022-3-1":                    time.Date(2022, 3, 1, 0, 0, 0, 0, berlin),
+		"15/06/2022":                  time.Date(2022, 6, 15, 0, 0, 0, 0, berlin),
+		"2022-06-15 10:30:00":         time.Date(2022, 6, 15, 10, 30, 0, 0, berlin),
+		"2022-06-15T10:30:00Z":        time.Date(2022, 6, 15, 10, 30, 0, 0, time.UTC),
+		"2022-06-15T10:30:00+02:00":   time.Date(2022, 6, 15, 8, 30, 0, 0, time.UTC),
+		"2022-06-15T10:30:00":         time.Date(2022, 6, 15, 10, 30, 0, 0, berlin),
+		"2022-06-15T10:30:00.5":       time.Date(2022, 6, 15, 10, 30, 0, 5e8, berlin),
+		"2022-06-15T10:30:00+0200":    time.Date(2022, 6, 15, 8, 30, 0, 0, time.UTC),
+		"2022-06-15T10:30:00.25Z":     time.Date(2022, 6, 15, 10, 30, 0, 25e7, time.UTC),
+		"2022-06-15 10:30:00 +02:00":  time.Date(2022, 6, 15, 8, 30, 0, 0, time.UTC),
+		"2022-06-15 10:30:00.5 -0100": time.Date(2022, 6, 15, 11, 30, 0, 5e8, time.UTC),
+		"2022-06-15 10:30:00Z":        time.Date(2022, 6, 15, 10, 30, 0, 0, time.UTC),
+	}
+
+	for input, expected := range tests {
+		actual, ok := b.parseDate(input)
+		if !ok {
+			t.Errorf("expected %v to parse", input)
+		} else if !actual.Equal(expected) {
+			t.Errorf("expected %v to parse as %s, got %s", input, expected, actual)
+		}
+	}
+
+	if _, ok := b.parseDate("tomorrow"); ok {
+		t.Errorf(`expected "tomorrow" not to parse`)
+	}
+}
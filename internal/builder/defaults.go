@@ -0,0 +1,119 @@
+package builder
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/danprince/sietch/internal/errors"
+)
+
+// The files that can set default front matter for the pages in a directory.
+var defaultsFiles = []string{"_defaults.yaml", "_defaults.yml", "_defaults.json", "_dir.json"}
+
+// A default front matter value, and the file that it came from.
+type defaultValue struct {
+	value any
+	file  string
+}
+
+// Fills in the front matter that the page doesn't set itself from the
+// defaults files in its directory and the directories above it. Deeper
+// directories override the ones above them. The page remembers which file
+// each of these keys came from, so that errors can point there instead.
+func (b *Builder) applyDefaults(page *Page) error {
+	page.defaulted = map[string]string{}
+	dirs := []string{"/"}
+	dir := ""
+
+	for _, part := range strings.Split(strings.Trim(page.Dir, "/"), "/") {
+		if part != "" {
+			dir += "/" + part
+			dirs = append(dirs, dir)
+		}
+	}
+
+	// Start from the closest directory, so that the first value we see for
+	// each key wins.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		defaults, err := b.dirDefaults(dirs[i])
+		if err != nil {
+			return err
+		}
+
+		for _, name := range defaultsFiles {
+			page.addDep(path.Join(b.PagesDir, dirs[i], name))
+		}
+
+		for key, d := range defaults {
+			if _, ok := page.Data[key]; !ok {
+				page.Data[key] = d.value
+				page.defaulted[key] = d.file
+			}
+		}
+	}
+
+	return nil
+}
+
+// Reads the defaults for the pages in dir (relative to the pages dir). If
+// there is more than one defaults file, the earlier ones in defaultsFiles
+// take precedence.
+func (b *Builder) dirDefaults(dir string) (map[string]defaultValue, error) {
+	b.defaultsMu.Lock()
+	defer b.defaultsMu.Unlock()
+
+	if defaults, ok := b.defaults[dir]; ok {
+		return defaults, nil
+	}
+
+	defaults := map[string]defaultValue{}
+
+	for i := len(defaultsFiles) - 1; i >= 0; i-- {
+		file := path.Join(b.PagesDir, dir, defaultsFiles[i])
+		contents, err := os.ReadFile(file)
+
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Wrap("defaults", err)
+		}
+
+		value, err := dataParsers[path.Ext(file)](file, contents)
+		if err != nil {
+			return nil, err
+		}
+
+		values, ok := value.(map[string]any)
+
+		if !ok && value != nil {
+			return nil, errors.Wrap("defaults", fmt.Errorf("%s should contain keys and values", file))
+		}
+
+		for key, value := range values {
+			defaults[key] = defaultValue{wholeNumberToInt(value), file}
+		}
+	}
+
+	b.defaults[dir] = defaults
+	return defaults, nil
+}
+
+// Forgets the defaults that have been read, so that they're read from disk
+// again when they're next needed.
+func (b *Builder) clearDefaults() {
+	b.defaultsMu.Lock()
+	defer b.defaultsMu.Unlock()
+	b.defaults = map[string]map[string]defaultValue{}
+}
+
+// JSON files decode every number as a float64, but keys like "paginate" and
+// "weight" expect ints, like the ones that YAML front matter would give.
+func wholeNumberToInt(value any) any {
+	if f, ok := value.(float64); ok && f == math.Trunc(f) && math.Abs(f) < math.MaxInt32 {
+		return int(f)
+	}
+	return value
+}
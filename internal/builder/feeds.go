@@ -0,0 +1,289 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The maximum number of pages to include in each feed.
+const feedSize = 20
+
+// The files that are written next to each page with a feed.
+var feedFiles = []string{"feed.xml", "atom.xml", "feed.json"}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Guid        string `xml:"guid"`
+	PubDate     string `xml:"pubDate,omitempty"`
+	Description string `xml:"description"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	Id      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	Id      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageUrl string         `json:"home_page_url"`
+	FeedUrl     string         `json:"feed_url"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	Id            string `json:"id"`
+	Url           string `json:"url"`
+	Title         string `json:"title"`
+	ContentHtml   string `json:"content_html"`
+	DatePublished string `json:"date_published,omitempty"`
+	DateModified  string `json:"date_modified,omitempty"`
+}
+
+// The url of the page's RSS feed, or an empty string if it doesn't have one.
+// Feeds are written next to the page, so news.html's feed is at feed.xml in
+// the same directory.
+func (p *Page) FeedUrl() string {
+	if p.Data["feed"] != true || p.virtual {
+		return ""
+	}
+	return path.Join(p.urlDir(), "feed.xml")
+}
+
+// Writes RSS, Atom and JSON feeds for each page that sets "feed: true" in its
+// front matter. The feeds contain the newest pages from that page's index.
+func (b *Builder) writeFeeds() error {
+	for _, page := range b.pages {
+		if page.Data["feed"] != true || page.virtual {
+			continue
+		}
+
+		if b.config.BaseUrl == "" {
+			return frontMatterError(page, "feed", "feeds need a BaseUrl in the config")
+		}
+
+		items := make([]*Page, len(b.index[page.langDir]))
+		copy(items, b.index[page.langDir])
+		sortByDateDesc(items)
+
+		if len(items) > feedSize {
+			items = items[:feedSize]
+		}
+
+		dir := page.urlDir()
+
+		feeds := map[string]func(*Page, []*Page) ([]byte, error){
+			"feed.xml":  b.rssFeed,
+			"atom.xml":  b.atomFeed,
+			"feed.json": b.jsonFeed,
+		}
+
+		for _, name := range feedFiles {
+			data, err := feeds[name](page, items)
+			if err != nil {
+				return err
+			}
+
+			file := path.Join(b.OutDir, dir, name)
+
+			if err := os.MkdirAll(path.Dir(file), 0755); err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(file, data, 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *Builder) rssFeed(page *Page, items []*Page) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       pageTitle(page),
+			Link:        b.absUrl(page.Url),
+			Description: pageDescription(page),
+		},
+	}
+
+	if len(items) > 0 && !items[0].Date.IsZero() {
+		feed.Channel.LastBuildDate = items[0].Date.Format(time.RFC1123Z)
+	}
+
+	for _, item := range items {
+		rss := rssItem{
+			Title:       pageTitle(item),
+			Link:        b.absUrl(item.Url),
+			Guid:        b.absUrl(item.Url),
+			Description: b.absContent(item.body),
+		}
+
+		if !item.Date.IsZero() {
+			rss.PubDate = item.Date.Format(time.RFC1123Z)
+		}
+
+		feed.Channel.Items = append(feed.Channel.Items, rss)
+	}
+
+	return marshalXml(feed)
+}
+
+func (b *Builder) atomFeed(page *Page, items []*Page) ([]byte, error) {
+	feed := atomFeed{
+		Title: pageTitle(page),
+		Id:    b.absUrl(page.Url),
+		Links: []atomLink{
+			{Href: b.absUrl(page.Url)},
+			{Href: b.absUrl(path.Join(page.urlDir(), "atom.xml")), Rel: "self"},
+		},
+	}
+
+	// Items are sorted by their dates, so any of them could be the most
+	// recently updated.
+	updated := page.Updated
+
+	for _, item := range items {
+		if item.Updated.After(updated) {
+			updated = item.Updated
+		}
+	}
+
+	feed.Updated = b.atomDate(updated)
+
+	for _, item := range items {
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   pageTitle(item),
+			Id:      b.absUrl(item.Url),
+			Updated: b.atomDate(item.Updated),
+			Link:    atomLink{Href: b.absUrl(item.Url)},
+			Content: atomContent{Type: "html", Body: b.absContent(item.body)},
+		})
+	}
+
+	return marshalXml(feed)
+}
+
+// Atom needs a date for everything, so pages without dates use the time that
+// the site was built instead.
+func (b *Builder) atomDate(date time.Time) string {
+	if date.IsZero() {
+		date = b.site.BuildTime
+	}
+	return date.Format(time.RFC3339)
+}
+
+func (b *Builder) jsonFeed(page *Page, items []*Page) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       pageTitle(page),
+		HomePageUrl: b.absUrl(page.Url),
+		FeedUrl:     b.absUrl(path.Join(page.urlDir(), "feed.json")),
+		Items:       []jsonFeedItem{},
+	}
+
+	for _, item := range items {
+		jsonItem := jsonFeedItem{
+			Id:          b.absUrl(item.Url),
+			Url:         b.absUrl(item.Url),
+			Title:       pageTitle(item),
+			ContentHtml: b.absContent(item.body),
+		}
+
+		if !item.Date.IsZero() {
+			jsonItem.DatePublished = item.Date.Format(time.RFC3339)
+		}
+
+		if !item.Updated.Equal(item.Date) {
+			jsonItem.DateModified = item.Updated.Format(time.RFC3339)
+		}
+
+		feed.Items = append(feed.Items, jsonItem)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(feed)
+	return buf.Bytes(), err
+}
+
+func marshalXml(v any) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	return []byte(xml.Header + string(data) + "\n"), err
+}
+
+// Turns a url from the site into an absolute url, using the BaseUrl.
+func (b *Builder) absUrl(url string) string {
+	return strings.TrimSuffix(b.config.BaseUrl, "/") + url
+}
+
+var rootRelativeUrlRegex = regexp.MustCompile(`(href|src)="/([^/])`)
+
+// Makes root relative links in html absolute, so that they still work when
+// the html is shown outside of the site (e.g. in a feed reader).
+func (b *Builder) absContent(html string) string {
+	base := strings.TrimSuffix(b.config.BaseUrl, "/")
+	return rootRelativeUrlRegex.ReplaceAllString(html, fmt.Sprintf(`$1="%s/$2`, base))
+}
+
+func pageTitle(page *Page) string {
+	if title, ok := page.Data["title"].(string); ok {
+		return title
+	}
+	return page.Url
+}
+
+func pageDescription(page *Page) string {
+	if description, ok := page.Data["description"].(string); ok {
+		return description
+	}
+	return pageTitle(page)
+}
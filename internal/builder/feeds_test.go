@@ -0,0 +1,63 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAtomFeedDates(t *testing.T) {
+	b := New(".", Production)
+	b.site.BuildTime = time.Date(2022, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	page := &Page{Url: "/blog/", Data: map[string]any{}}
+	undated := &Page{Url: "/blog/undated.html", Data: map[string]any{}}
+	edited := &Page{
+		Url:     "/blog/edited.html",
+		Data:    map[string]any{},
+		Date:    time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Updated: time.Date(2022, 3, 5, 0, 0, 0, 0, time.UTC),
+	}
+	newer := &Page{
+		Url:     "/blog/newer.html",
+		Data:    map[string]any{},
+		Date:    time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC),
+		Updated: time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	data, err := b.atomFeed(page, []*Page{newer, edited})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), "<updated>2022-03-05T00:00:00Z</updated>\n  <link") {
+		t.Errorf("expected the feed to be updated when its latest item was, got %s", data)
+	}
+
+	data, err = b.atomFeed(page, []*Page{undated})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), "0001-01-01") || !strings.Contains(string(data), "2022-06-15T12:00:00Z") {
+		t.Errorf("expected undated items to use the build time, got %s", data)
+	}
+}
+
+func TestFeedUrl(t *testing.T) {
+	feed := map[string]any{"feed": true}
+
+	tests := map[*Page]string{
+		{Url: "/blog/", Data: feed}:             "/blog/feed.xml",
+		{Url: "/blog/news.html", Data: feed}:    "/blog/feed.xml",
+		{Url: "/news/", Data: feed}:             "/news/feed.xml",
+		{Url: "/", Data: feed}:                  "/feed.xml",
+		{Url: "/blog/", Data: map[string]any{}}: "",
+	}
+
+	for page, expected := range tests {
+		if actual := page.FeedUrl(); actual != expected {
+			t.Errorf("expected the feed url for %s to be %q, got %q", page.Url, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package builder
+
+import (
+	"path"
+	"strings"
+)
+
+// The extension for plain template pages. These are written to a file named
+// after the rest of their name (e.g. feed.xml.tmpl becomes feed.xml).
+const templateExt = ".tmpl"
+
+// Whether a file in the pages dir should be turned into a page. Markdown and
+// HTML files are pages, and so are plain templates.
+func isPageFile(file string) bool {
+	switch path.Ext(file) {
+	case ".md", ".html", templateExt:
+		return true
+	default:
+		return false
+	}
+}
+
+// Whether a file is the index page of its directory.
+func isIndexFile(file string) bool {
+	name := path.Base(file)
+	return name == "index.md" || name == "index.html"
+}
+
+// Whether the page's contents are markdown that needs converting to HTML.
+func (p *Page) isMarkdown() bool {
+	return path.Ext(p.Path) == ".md"
+}
+
+// Whether the page is a plain template, which isn't rendered into a layout
+// unless it asks for one, and isn't listed alongside other pages.
+func (p *Page) isPlain() bool {
+	return path.Ext(p.Path) == templateExt
+}
+
+// Whether the page is written as an HTML document.
+func (p *Page) isHtml() bool {
+	return strings.HasSuffix(p.Url, "/") || path.Ext(p.Url) == ".html"
+}
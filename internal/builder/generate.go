@@ -0,0 +1,167 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Replaces pages that set "generate" in their front matter with a page for
+// each item in the data collection they name. The urls of the new pages come
+// from the "permalink" key, where each token is replaced with a field from
+// the item (e.g. /products/:id/).
+func (b *Builder) generatePages() error {
+	var pages []*Page
+	generators := map[*Page][]*Page{}
+
+	for _, page := range b.pages {
+		if page.Data["generate"] == nil {
+			pages = append(pages, page)
+			continue
+		}
+
+		name, ok := page.Data["generate"].(string)
+
+		if !ok {
+			return frontMatterError(page, "generate", fmt.Sprintf("expected the name of a data file, got %v", page.Data["generate"]))
+		}
+
+		items, err := b.collection(page, name)
+		if err != nil {
+			return err
+		}
+
+		pattern, ok := page.Data["permalink"].(string)
+
+		if !ok {
+			return frontMatterError(page, "permalink", "generated pages need a permalink pattern (e.g. /products/:id/)")
+		}
+
+		for i, item := range items {
+			url, err := expandItemPermalink(pattern, item)
+
+			if err != nil {
+				return frontMatterError(page, "permalink", fmt.Sprintf("item %d %s", i, err))
+			}
+
+			generated, err := b.clonePage(page, fmt.Sprintf("%s#%d", page.Path, i))
+			if err != nil {
+				return err
+			}
+
+			generated.Item = item
+			generated.generated = true
+			generated.Data = map[string]any{}
+
+			for key, value := range page.Data {
+				generated.Data[key] = value
+			}
+
+			generated.Data["url"] = url
+
+			// Items can fill in the title and terms that the generator doesn't set.
+			for _, key := range append([]string{"title"}, b.config.Taxonomies...) {
+				if value, ok := itemField(item, key); ok && page.Data[key] == nil {
+					generated.Data[key] = value
+				}
+			}
+
+			pages = append(pages, generated)
+			generators[page] = append(generators[page], generated)
+		}
+
+		if generators[page] == nil {
+			generators[page] = []*Page{}
+		}
+	}
+
+	b.pages = pages
+
+	// The generators themselves aren't pages, so they're listed as the pages
+	// they generated instead.
+	for dir, pages := range b.index {
+		var listed []*Page
+
+		for _, page := range pages {
+			if generated, ok := generators[page]; ok {
+				listed = append(listed, generated...)
+			} else {
+				listed = append(listed, page)
+			}
+		}
+
+		b.index[dir] = listed
+	}
+
+	return nil
+}
+
+// Finds the list of items in the site's data that a generator page names.
+// Names can include dots to find items in nested data (e.g. shop.products).
+func (b *Builder) collection(page *Page, name string) ([]any, error) {
+	var value any = b.site.Data
+
+	for _, key := range strings.Split(name, ".") {
+		data, ok := value.(map[string]any)
+
+		if !ok {
+			value = nil
+			break
+		}
+
+		value = data[key]
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, frontMatterError(page, "generate", fmt.Sprintf(`no data called "%s" in _data`, name))
+	case []any:
+		return v, nil
+	case []map[string]any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items, nil
+	case []map[string]string:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, frontMatterError(page, "generate", fmt.Sprintf(`"%s" isn't a list`, name))
+	}
+}
+
+// Replaces the tokens in a permalink pattern with slugs of the fields from a
+// data item.
+func expandItemPermalink(pattern string, item any) (string, error) {
+	var err error
+
+	url := permalinkTokenRegex.ReplaceAllStringFunc(pattern, func(token string) string {
+		value, ok := itemField(item, token[1:])
+
+		if !ok && err == nil {
+			err = fmt.Errorf(`doesn't have a "%s" field`, token[1:])
+		}
+
+		return slugify(fmt.Sprint(value))
+	})
+
+	return normalizeUrl(url), err
+}
+
+// Finds a field in a data item. Items from csv files have string values, but
+// items from other formats can have values of any type.
+func itemField(item any, key string) (any, bool) {
+	switch v := item.(type) {
+	case map[string]any:
+		value, ok := v[key]
+		return value, ok
+	case map[string]string:
+		value, ok := v[key]
+		return value, ok
+	default:
+		return nil, false
+	}
+}
@@ -6,6 +6,10 @@ import (
 	"io"
 	"os"
 	"path"
+	"sort"
+
+	"github.com/danprince/sietch/internal/errors"
+	"golang.org/x/sync/errgroup"
 )
 
 func shortHash(s string) string {
@@ -14,6 +18,18 @@ func shortHash(s string) string {
 	return fmt.Sprintf("%x", h.Sum32())[:4]
 }
 
+// Creates an error that points at the line where key is defined in the
+// page's front matter, or in the defaults file that the page got it from.
+func frontMatterError(page *Page, key string, message string) error {
+	if file, ok := page.defaulted[key]; ok {
+		contents, _ := os.ReadFile(file)
+		return errors.FrontMatterError(file, string(contents), key, fmt.Sprintf("%s (in the defaults for %s)", message, page.Path))
+	}
+
+	contents, _ := os.ReadFile(page.inputPath)
+	return errors.FrontMatterError(page.inputPath, string(contents), key, message)
+}
+
 // Implements a less comparator for sorting for any pair of values. These
 // values almost certainly come from the front matter section of pages, so
 // we never know their actual type upfront.
@@ -35,6 +51,25 @@ func lessAny(a any, b any) bool {
 	return false
 }
 
+// Sorts pages from newest to oldest.
+func sortByDateDesc(pages []*Page) {
+	sort.SliceStable(pages, func(i, j int) bool {
+		return pages[i].Date.After(pages[j].Date)
+	})
+}
+
+// Calls fn for each page concurrently.
+func eachPage(pages []*Page, fn func(page *Page) error) error {
+	var g errgroup.Group
+	for _, page := range pages {
+		p := page
+		g.Go(func() error {
+			return fn(p)
+		})
+	}
+	return g.Wait()
+}
+
 func copyFile(src string, dst string) error {
 	dir := path.Dir(dst)
 
@@ -0,0 +1,244 @@
+package builder
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/danprince/sietch/internal/errors"
+)
+
+// Language is one of the languages that a site is written in.
+type Language struct {
+	// The code that identifies the language in file names and urls (e.g. "de").
+	Code string
+
+	// The name to show for the language (e.g. "Deutsch").
+	Name string
+}
+
+var languageCodeRegex = regexp.MustCompile(`^[A-Za-z0-9]+(-[A-Za-z0-9]+)*$`)
+
+// The language that pages are written in unless they say otherwise. This is
+// the first language in the config, or an empty string if the site only has
+// one language.
+func (b *Builder) defaultLang() string {
+	if len(b.config.Languages) == 0 {
+		return ""
+	}
+	return b.config.Languages[0].Code
+}
+
+// Whether code is one of the site's languages.
+func (b *Builder) isLang(code string) bool {
+	for _, lang := range b.config.Languages {
+		if lang.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Works out which language a page is written in from its path, relative to
+// the pages dir. Translations either have the language before their extension
+// (about.de.md) or live in a top level directory for the language
+// (de/about.md). Also returns the path with the language removed, which is
+// the same for every translation of a page.
+func (b *Builder) pathLang(relPath string) (string, string) {
+	name := path.Base(relPath)
+	tmpl := ""
+
+	// Plain templates have the language before their real extension
+	// (feed.de.xml.tmpl).
+	if path.Ext(name) == templateExt {
+		name = strings.TrimSuffix(name, templateExt)
+		tmpl = templateExt
+	}
+
+	ext := path.Ext(name)
+	suffix := path.Ext(strings.TrimSuffix(name, ext))
+
+	if suffix != "" && b.isLang(suffix[1:]) {
+		name = strings.TrimSuffix(name, suffix+ext) + ext + tmpl
+		return suffix[1:], path.Join(path.Dir(relPath), name)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(relPath, "/"), "/", 2)
+
+	if len(parts) == 2 && b.isLang(parts[0]) {
+		return parts[0], "/" + parts[1]
+	}
+
+	return b.defaultLang(), relPath
+}
+
+// The start of the urls for pages in a language. Pages in the default
+// language don't have a prefix.
+func (b *Builder) langPrefix(lang string) string {
+	if lang == b.defaultLang() {
+		return ""
+	}
+	return "/" + lang
+}
+
+// Links together the pages that are translations of each other. Translations
+// are ordered in the same way as the languages in the config.
+func (b *Builder) linkTranslations() {
+	translations := map[string][]*Page{}
+
+	for _, page := range b.site.pages {
+		page.Translations = nil
+		translations[page.key] = append(translations[page.key], page)
+	}
+
+	for _, lang := range b.config.Languages {
+		for _, page := range b.site.pages {
+			for _, other := range translations[page.key] {
+				if other != page && other.Lang == lang.Code {
+					page.Translations = append(page.Translations, other)
+				}
+			}
+		}
+	}
+}
+
+// Finds the translation of page in lang, or returns page itself if there
+// isn't one.
+func translation(page *Page, lang string) *Page {
+	for _, other := range page.Translations {
+		if other.Lang == lang {
+			return other
+		}
+	}
+	return page
+}
+
+// Creates a function that resolves links to markdown files to the url of the
+// page that the file became, in the same language as page.
+func (b *Builder) linkResolver(page *Page) func(string) (string, bool) {
+	return func(dest string) (string, bool) {
+		file := path.Join(b.PagesDir, dest)
+
+		if !path.IsAbs(dest) {
+			file = path.Join(path.Dir(page.inputPath), dest)
+		}
+
+		target := b.findPage(file)
+
+		if target == nil {
+			return "", false
+		}
+
+		url := translation(target, page.Lang).Url
+
+		if path.IsAbs(dest) {
+			return url, true
+		}
+
+		// Relative links stay relative, in the same style they were written.
+		rel := relativeUrl(page.urlDir(), url)
+
+		if strings.HasPrefix(dest, "./") && !strings.HasPrefix(rel, ".") {
+			rel = "./" + rel
+		}
+
+		return rel, true
+	}
+}
+
+// Reads the string tables from the i18n dir. Each file is named after the
+// language it translates into (e.g. _i18n/de.yaml) and can be in any of the
+// formats that data files can. Nested keys are joined with dots.
+//
+// Sites without languages can have a single file (e.g. _i18n/en.yaml), which
+// is used for every page.
+func (b *Builder) readI18n() error {
+	b.i18n = map[string]map[string]string{}
+	single := ""
+
+	info, err := os.Stat(b.i18nDir)
+
+	if os.IsNotExist(err) || (err == nil && !info.IsDir()) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap("i18n", err)
+	}
+
+	return filepath.WalkDir(b.i18nDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		parse, ok := dataParsers[path.Ext(file)]
+
+		if !ok || path.Ext(file) == ".csv" {
+			return nil
+		}
+
+		lang := strings.TrimSuffix(path.Base(file), path.Ext(file))
+
+		if len(b.config.Languages) == 0 {
+			if single != "" {
+				return errors.Wrap("i18n", fmt.Errorf(`found "%s" and "%s", but sites without Languages in the config can only have one file in _i18n`, single, path.Base(file)))
+			}
+			single = path.Base(file)
+			lang = b.defaultLang()
+		} else if !b.isLang(lang) {
+			return errors.Wrap("i18n", fmt.Errorf(`"%s" isn't one of the languages in the config`, lang))
+		}
+
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return errors.Wrap("i18n", err)
+		}
+
+		value, err := parse(file, contents)
+		if err != nil {
+			return err
+		}
+
+		if b.i18n[lang] == nil {
+			b.i18n[lang] = map[string]string{}
+		}
+
+		flattenStrings(b.i18n[lang], "", value)
+		return nil
+	})
+}
+
+// Whether file is in the i18n dir.
+func (b *Builder) isI18nFile(file string) bool {
+	return strings.HasPrefix(file, b.i18nDir+"/")
+}
+
+// Looks up the string for key in lang, falling back to the default language
+// if that language doesn't have one.
+func (b *Builder) translate(lang string, key string) (string, error) {
+	if s, ok := b.i18n[lang][key]; ok {
+		return s, nil
+	}
+
+	if s, ok := b.i18n[b.defaultLang()][key]; ok {
+		return s, nil
+	}
+
+	return "", fmt.Errorf(`no string called "%s" in _i18n`, key)
+}
+
+func flattenStrings(table map[string]string, prefix string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, val := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenStrings(table, key, val)
+		}
+	default:
+		table[prefix] = fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,177 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/danprince/sietch/internal/errors"
+)
+
+// A set of templates that pages can be rendered into.
+type layout struct {
+	template *template.Template
+
+	// The name of the template in the set to execute.
+	entry string
+
+	// The files the layout was built from, including the ones that don't exist
+	// (yet) but would change the layout if they did.
+	files []string
+}
+
+// Finds the layout for a page. Layouts start from the global template, then
+// include the _template.html file from each directory above the page, then
+// the file from _layouts that the page names with its "layout" key.
+//
+// Each of these templates can override blocks from the templates before it,
+// and if it has a body of its own (anything other than "define" blocks) then
+// it replaces the page's markup completely.
+//
+// Pages that set "layout: false" don't have a layout, and neither do plain
+// templates, unless they name one.
+func (b *Builder) pageLayout(page *Page) (*layout, error) {
+	name, ok := page.Data["layout"].(string)
+
+	if page.Data["layout"] == false || (page.isPlain() && !ok) {
+		return nil, nil
+	}
+
+	l, err := b.dirLayout(page.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok || name == "" {
+		return l, nil
+	}
+
+	key := page.Dir + ":" + name
+	if cached, ok := b.cachedLayout(key); ok {
+		return cached, nil
+	}
+
+	file := path.Join(b.layoutsDir, name+".html")
+
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return nil, frontMatterError(page, "layout", fmt.Sprintf(`no layout called "%s" in _layouts`, name))
+	}
+
+	l, err = b.extendLayout(l, file)
+	if err != nil {
+		return nil, err
+	}
+
+	b.cacheLayout(key, l)
+	return l, nil
+}
+
+// Finds the layout that applies to all pages in dir.
+func (b *Builder) dirLayout(dir string) (*layout, error) {
+	if l, ok := b.cachedLayout(dir); ok {
+		return l, nil
+	}
+
+	var l *layout
+	var err error
+
+	if dir == "/" {
+		l = &layout{
+			template: b.template,
+			entry:    b.template.Name(),
+			files:    []string{b.templateFile},
+		}
+	} else {
+		l, err = b.dirLayout(path.Dir(dir))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	file := path.Join(b.PagesDir, dir, "_template.html")
+
+	// The global template might also live in the pages dir.
+	if file != b.templateFile {
+		l, err = b.extendLayout(l, file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b.cacheLayout(dir, l)
+	return l, nil
+}
+
+// Creates a new layout by parsing file on top of an existing one.
+func (b *Builder) extendLayout(parent *layout, file string) (*layout, error) {
+	files := append([]string{}, parent.files...)
+	files = append(files, file)
+
+	contents, err := os.ReadFile(file)
+
+	if os.IsNotExist(err) {
+		return &layout{template: parent.template, entry: parent.entry, files: files}, nil
+	} else if err != nil {
+		return nil, errors.Wrap("template", err)
+	}
+
+	t, err := parent.template.Clone()
+	if err != nil {
+		return nil, errors.Wrap("template", err)
+	}
+
+	// Templates are named after their files, so that errors during execution
+	// can be traced back to the right one.
+	t, err = t.New(file).Parse(string(contents))
+	if err != nil {
+		return nil, errors.TemplateParseError(err, file, string(contents), 0)
+	}
+
+	entry := parent.entry
+
+	if !parse.IsEmptyTree(t.Tree.Root) {
+		entry = file
+	}
+
+	return &layout{template: t, entry: entry, files: files}, nil
+}
+
+func (b *Builder) cachedLayout(key string) (*layout, bool) {
+	b.layoutsMu.Lock()
+	defer b.layoutsMu.Unlock()
+	l, ok := b.layouts[key]
+	return l, ok
+}
+
+func (b *Builder) cacheLayout(key string, l *layout) {
+	b.layoutsMu.Lock()
+	defer b.layoutsMu.Unlock()
+	b.layouts[key] = l
+}
+
+// Forgets layouts from previous builds, so that they're parsed again.
+func (b *Builder) clearLayouts() {
+	b.layoutsMu.Lock()
+	defer b.layoutsMu.Unlock()
+	b.layouts = map[string]*layout{}
+}
+
+var templateErrorNameRegex = regexp.MustCompile(`^template: (.+?):\d+:\d+: `)
+
+// Works out which file an error from executing a layout came from. Blocks
+// can be defined in any of the layout's templates, or in the page itself.
+func (b *Builder) layoutExecError(err error, page *Page) error {
+	file := b.templateFile
+
+	if m := templateErrorNameRegex.FindStringSubmatch(err.Error()); m != nil {
+		if m[1] == page.template.Name() {
+			return errors.TemplateExecError(err, page.inputPath, page.src, page.contentStartLine)
+		}
+		file = m[1]
+	}
+
+	return errors.TemplateExecError(err, file, "", 0)
+}
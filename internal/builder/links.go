@@ -0,0 +1,220 @@
+package builder
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/danprince/sietch/internal/errors"
+)
+
+var (
+	linkAttrRegex = regexp.MustCompile(`\s(?:href|src)=(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	idAttrRegex   = regexp.MustCompile(`\sid=(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// The ways that broken links can be reported.
+var linkCheckModes = map[string]bool{
+	"warn":  true,
+	"error": true,
+	"off":   true,
+}
+
+// Decides how broken links are reported. Unless the config says otherwise,
+// they're warnings during development and errors in production.
+func (b *Builder) linkCheckMode() string {
+	if b.config.CheckLinks != "" {
+		return b.config.CheckLinks
+	}
+	if b.Mode == Production {
+		return "error"
+	}
+	return "warn"
+}
+
+// Checks that the internal links (and the anchors they point to) in pages
+// lead somewhere in the output directory. This needs to happen after every
+// file has been written.
+func (b *Builder) checkLinks(pages []*Page) error {
+	mode := b.linkCheckMode()
+
+	for _, page := range pages {
+		page.brokenLinks = nil
+	}
+
+	if mode == "off" {
+		return nil
+	}
+
+	ids := map[string]map[string]bool{}
+
+	for _, page := range b.pages {
+		ids[page.outputPath] = map[string]bool{}
+		for _, m := range idAttrRegex.FindAllStringSubmatch(page.Contents, -1) {
+			ids[page.outputPath][attrValue(m)] = true
+		}
+	}
+
+	for _, page := range pages {
+		seen := map[string]bool{}
+
+		// Links in other formats don't always mean the same thing.
+		if !page.isHtml() {
+			continue
+		}
+
+		for _, m := range linkAttrRegex.FindAllStringSubmatch(page.Contents, -1) {
+			link := attrValue(m)
+
+			if seen[link] {
+				continue
+			}
+
+			seen[link] = true
+
+			if message := b.checkLink(page, link, ids); message != "" {
+				page.brokenLinks = append(page.brokenLinks, b.brokenLinkError(page, link, message))
+			}
+		}
+	}
+
+	// The link that stops the build isn't a warning too, but any other broken
+	// links are, so that they can still be reported.
+	if mode == "error" {
+		for _, page := range pages {
+			if len(page.brokenLinks) > 0 {
+				err := page.brokenLinks[0]
+				page.brokenLinks = page.brokenLinks[1:]
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Checks a single link from a page. Returns a message explaining what's wrong
+// if the link is broken.
+func (b *Builder) checkLink(page *Page, link string, ids map[string]map[string]bool) string {
+	u, err := url.Parse(link)
+
+	// Only links within the site can be checked.
+	if err != nil || u.Scheme != "" || u.Host != "" || strings.HasPrefix(link, "//") {
+		return ""
+	}
+
+	file := page.outputPath
+
+	if u.Path != "" {
+		p := u.Path
+
+		if !path.IsAbs(p) {
+			p = path.Join(page.urlDir(), p)
+			if strings.HasSuffix(u.Path, "/") {
+				p += "/"
+			}
+		}
+
+		file = b.resolveOutput(p)
+
+		if file == "" {
+			return fmt.Sprintf(`"%s" doesn't exist`, link)
+		}
+	}
+
+	// Anchors can only be checked in pages.
+	if anchors, ok := ids[file]; ok && u.Fragment != "" && !anchors[u.Fragment] {
+		return fmt.Sprintf(`"%s" has no element with the id "%s"`, link, u.Fragment)
+	}
+
+	return ""
+}
+
+// Finds the file in the output directory that a url would be served from, in
+// the same way that the dev server does. Returns an empty string if there
+// isn't one.
+func (b *Builder) resolveOutput(url string) string {
+	file := path.Join(b.OutDir, url)
+
+	if strings.HasSuffix(url, "/") {
+		file = path.Join(file, "index.html")
+	}
+
+	info, err := os.Stat(file)
+
+	if err == nil && info.IsDir() {
+		file = path.Join(file, "index.html")
+		_, err = os.Stat(file)
+	} else if err != nil && path.Ext(file) == "" {
+		file += ".html"
+		_, err = os.Stat(file)
+	}
+
+	if err != nil {
+		return ""
+	}
+
+	return file
+}
+
+// Creates an error that points at the place where a broken link came from.
+// Links can come from the page itself, or from its layout and partials. Links
+// to markdown files are rewritten, so we look for those names too.
+func (b *Builder) brokenLinkError(page *Page, link string, message string) error {
+	var partials []string
+
+	for _, file := range b.partialFiles {
+		partials = append(partials, file)
+	}
+
+	sort.Strings(partials)
+
+	files := []string{page.inputPath}
+
+	if page.layout != nil {
+		files = append(files, page.layout.files...)
+	}
+
+	files = append(files, partials...)
+
+	candidates := []string{link}
+	name := strings.TrimSuffix(path.Base(strings.Split(link, "#")[0]), ".html")
+
+	if name != "" && name != "." && name != "/" {
+		candidates = append(candidates, name+".md")
+	}
+
+	for _, candidate := range candidates {
+		for _, file := range files {
+			contents, err := os.ReadFile(file)
+
+			if err == nil && strings.Contains(string(contents), candidate) {
+				return errors.BrokenLinkError(file, string(contents), candidate, message)
+			}
+		}
+	}
+
+	contents, _ := os.ReadFile(page.inputPath)
+	return errors.BrokenLinkError(page.inputPath, string(contents), link, message)
+}
+
+// Problems from the last build that didn't stop the site from being built,
+// such as broken links during development.
+func (b *Builder) Warnings() []error {
+	var warnings []error
+
+	for _, page := range b.pages {
+		warnings = append(warnings, page.brokenLinks...)
+	}
+
+	return warnings
+}
+
+func attrValue(m []string) string {
+	return html.UnescapeString(m[1] + m[2] + m[3])
+}
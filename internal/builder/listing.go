@@ -0,0 +1,129 @@
+package builder
+
+import (
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// The template functions that return other pages.
+var listingFuncs = map[string]bool{
+	"index":       true,
+	"page":        true,
+	"pagesWith":   true,
+	"terms":       true,
+	"pagesByTerm": true,
+}
+
+// The fields that lead from a page to other pages.
+var listingFields = map[string]bool{
+	"Pages":        true,
+	"Sections":     true,
+	"Parent":       true,
+	"Children":     true,
+	"Ancestors":    true,
+	"Prev":         true,
+	"Next":         true,
+	"Translations": true,
+}
+
+// Whether a template can show other pages, which means it has to be built
+// after them to see their summaries. This errs on the side of caution, so a
+// template that mentions .Parent counts, even if it only uses its title.
+func (b *Builder) templateListsPages(t *template.Template) bool {
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree == nil {
+			continue
+		}
+
+		lists, callsPartial := nodeListsPages(tmpl.Tree.Root)
+
+		if lists {
+			return true
+		}
+
+		// Partials are parsed before pages, so we can check them too.
+		if callsPartial && b.partials != nil && t != b.partials && b.templateListsPages(b.partials) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Walks a template's parse tree looking for functions and fields that lead
+// to other pages, and for calls to "partial".
+func nodeListsPages(node parse.Node) (lists bool, callsPartial bool) {
+	var walk func(node parse.Node)
+
+	fields := func(idents []string) {
+		for _, ident := range idents {
+			if listingFields[ident] {
+				lists = true
+			}
+		}
+	}
+
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.IdentifierNode:
+			if listingFuncs[n.Ident] {
+				lists = true
+			}
+			if n.Ident == "partial" {
+				callsPartial = true
+			}
+		case *parse.FieldNode:
+			fields(n.Ident)
+		case *parse.VariableNode:
+			fields(n.Ident)
+		case *parse.ChainNode:
+			walk(n.Node)
+			fields(n.Field)
+		case *parse.IfNode:
+			walk(&n.BranchNode)
+		case *parse.RangeNode:
+			walk(&n.BranchNode)
+		case *parse.WithNode:
+			walk(&n.BranchNode)
+		case *parse.BranchNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		}
+	}
+
+	walk(node)
+	return lists, callsPartial
+}
+
+// The number of directories that dir is nested inside the pages dir.
+func dirDepth(dir string) int {
+	dir = strings.Trim(dir, "/")
+	if dir == "" {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
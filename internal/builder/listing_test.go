@@ -0,0 +1,28 @@
+package builder
+
+import (
+	"testing"
+	"text/template"
+)
+
+func TestTemplateListsPages(t *testing.T) {
+	b := New(".", Production)
+
+	tests := map[string]bool{
+		`{{ .Data.title }}`:                                       false,
+		`{{ .Summary }} {{ formatDate "2006" .Date }}`:            false,
+		`{{ range index }}{{ .Summary }}{{ end }}`:                true,
+		`{{ range sortBy "title" (pagesWith "tags") }}{{ end }}`:  true,
+		`{{ with .Parent }}{{ .Url }}{{ end }}`:                   true,
+		`{{ range $.Site.Pages }}{{ end }}`:                       true,
+		`{{ if .Draft }}{{ else }}{{ .Next.Url }}{{ end }}`:       true,
+		`{{ define "x" }}{{ range .Children }}{{ end }}{{ end }}`: true,
+	}
+
+	for src, expected := range tests {
+		tmpl := template.Must(template.New("page").Funcs(b.templateFuncs(nil)).Parse(src))
+		if actual := b.templateListsPages(tmpl); actual != expected {
+			t.Errorf("expected %s to be %v, got %v", src, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package builder
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Links each page to its parent, children and siblings, using the same
+// directory structure as "index". Index pages are the parents of the pages
+// that "index" returns for them.
+func (b *Builder) linkPages() {
+	indexPages := map[string]*Page{}
+
+	for _, page := range b.site.pages {
+		page.Parent = nil
+		page.Children = nil
+		page.Ancestors = nil
+		page.Prev = nil
+		page.Next = nil
+
+		if isIndexFile(page.key) {
+			indexPages[page.langDir] = page
+		}
+	}
+
+	for dir, pages := range b.index {
+		siblings := make([]*Page, len(pages))
+		copy(siblings, pages)
+		sortByWeight(siblings)
+
+		if parent, ok := indexPages[dir]; ok {
+			parent.Children = siblings
+		}
+
+		for i, page := range siblings {
+			if i > 0 {
+				page.Prev = siblings[i-1]
+			}
+			if i < len(siblings)-1 {
+				page.Next = siblings[i+1]
+			}
+		}
+	}
+
+	for _, page := range b.site.pages {
+		dir := page.langDir
+		root := path.Join("/", b.langPrefix(page.Lang))
+
+		// The root page of each language doesn't have a parent.
+		if isIndexFile(page.key) && path.Dir(page.key) == "/" {
+			continue
+		}
+
+		if isIndexFile(page.key) {
+			dir = path.Dir(dir)
+		}
+
+		// Directories without index pages are skipped over.
+		for page.Parent == nil {
+			if parent, ok := indexPages[dir]; ok && parent != page {
+				page.Parent = parent
+			} else if dir == root || dir == "/" {
+				break
+			} else {
+				dir = path.Dir(dir)
+			}
+		}
+	}
+
+	for _, page := range b.site.pages {
+		for p := page.Parent; p != nil; p = p.Parent {
+			page.Ancestors = append([]*Page{p}, page.Ancestors...)
+		}
+	}
+}
+
+// Finds the pages that show something about page through their parents,
+// children, siblings, ancestors, or translations.
+func (b *Builder) relatedPages(page *Page) []*Page {
+	related := append([]*Page{page.Parent, page.Prev, page.Next}, page.Children...)
+	related = append(related, page.Translations...)
+
+	for _, p := range b.site.pages {
+		for _, ancestor := range p.Ancestors {
+			if ancestor == page {
+				related = append(related, p)
+			}
+		}
+	}
+
+	return related
+}
+
+// Sorts pages by the "weight" key in their front matter, then from newest to
+// oldest, then by title. Pages without weights go after the ones with them.
+func sortByWeight(pages []*Page) {
+	sort.SliceStable(pages, func(i, j int) bool {
+		a, b := pages[i], pages[j]
+		wa, okA := a.Data["weight"].(int)
+		wb, okB := b.Data["weight"].(int)
+
+		if okA != okB {
+			return okA
+		}
+
+		if okA && wa != wb {
+			return wa < wb
+		}
+
+		if !a.Date.Equal(b.Date) {
+			return a.Date.After(b.Date)
+		}
+
+		return strings.ToLower(pageTitle(a)) < strings.ToLower(pageTitle(b))
+	})
+}
@@ -0,0 +1,151 @@
+package builder
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Paginator splits the pages that would be returned by "index" into smaller
+// groups, with one output page for each group.
+type Paginator struct {
+	// The pages in this group.
+	Pages []*Page
+
+	// The 1-based number of this group.
+	PageNumber int
+
+	// The total number of groups.
+	TotalPages int
+
+	// The output page for each group, in order.
+	pagers []*Page
+}
+
+// The url of the previous page, or an empty string on the first page.
+func (p *Paginator) PrevUrl() string {
+	if p.PageNumber <= 1 {
+		return ""
+	}
+	return p.pagers[p.PageNumber-2].Url
+}
+
+// The url of the next page, or an empty string on the last page.
+func (p *Paginator) NextUrl() string {
+	if p.PageNumber >= p.TotalPages {
+		return ""
+	}
+	return p.pagers[p.PageNumber].Url
+}
+
+// Creates the extra pages for any page that sets "paginate" in its front
+// matter. The original page becomes the first page, and copies of it are
+// added to the builder for the rest.
+func (b *Builder) paginatePages() error {
+	for _, page := range b.pages {
+		if page.Data["paginate"] == nil {
+			continue
+		}
+
+		size, ok := page.Data["paginate"].(int)
+
+		if !ok || size <= 0 {
+			return frontMatterError(page, "paginate", fmt.Sprintf("expected a positive number, got %v", page.Data["paginate"]))
+		}
+
+		// Copy the index before sorting to avoid changing it for other pages.
+		children := make([]*Page, len(b.index[page.langDir]))
+		copy(children, b.index[page.langDir])
+
+		sortByDateDesc(children)
+
+		total := (len(children) + size - 1) / size
+
+		if total == 0 {
+			total = 1
+		}
+
+		pagers := []*Page{page}
+
+		for n := 2; n <= total; n++ {
+			pager, err := b.copyPage(page, fmt.Sprintf("page/%d", n))
+			if err != nil {
+				return err
+			}
+			pagers = append(pagers, pager)
+		}
+
+		for i, pager := range pagers {
+			start := i * size
+			end := start + size
+
+			if end > len(children) {
+				end = len(children)
+			}
+
+			pager.listsPages = true
+			pager.Paginator = &Paginator{
+				Pages:      children[start:end],
+				PageNumber: i + 1,
+				TotalPages: total,
+				pagers:     pagers,
+			}
+		}
+
+		b.pages = append(b.pages, pagers[1:]...)
+	}
+
+	return nil
+}
+
+// Creates a copy of a page that is written to a subpath of the original
+// page's url. Copies aren't included in "index" or "pagesWith".
+func (b *Builder) copyPage(page *Page, subpath string) (*Page, error) {
+	copy, err := b.clonePage(page, path.Join(page.Path, subpath))
+	if err != nil {
+		return nil, err
+	}
+
+	copy.source = page
+	copy.subpath = subpath
+	return copy, nil
+}
+
+// Creates a virtual page from the same file as page. The name is used to
+// give the clone a unique id.
+func (b *Builder) clonePage(page *Page, name string) (*Page, error) {
+	copy := *page
+	copy.id = shortHash(name)
+	copy.virtual = true
+	copy.islands = nil
+	copy.deps = map[string]bool{}
+
+	for dep := range page.deps {
+		copy.deps[dep] = true
+	}
+
+	// The template needs to be bound to the copy, rather than the original.
+	tmpl, err := page.template.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	copy.template = tmpl.Funcs(b.templateFuncs(&copy))
+	return &copy, nil
+}
+
+// Whether other pages can list the page. Virtual pages are usually copies
+// of other pages (like the other pages of a paginated list) or exist to list
+// pages themselves, but pages generated from data stand in for pages that
+// would otherwise be written by hand.
+func (p *Page) isListable() bool {
+	return !p.virtual || p.generated
+}
+
+// The url for a copy of a page.
+func subpathUrl(url string, subpath string) string {
+	if !strings.HasSuffix(url, "/") {
+		url = strings.TrimSuffix(url, path.Ext(url)) + "/"
+	}
+	return url + subpath + "/"
+}
@@ -0,0 +1,97 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/danprince/sietch/internal/errors"
+)
+
+// Reads and parses every file in the partials dir. Partials are named after
+// their path inside the dir, without an extension (e.g. _partials/blog/card.html
+// is called "blog/card").
+func (b *Builder) readPartials() error {
+	b.partials = template.New("partials").Funcs(b.templateFuncs(nil))
+	b.partialFiles = map[string]string{}
+	b.partialSrcs = map[string]string{}
+
+	info, err := os.Stat(b.partialsDir)
+
+	if os.IsNotExist(err) || (err == nil && !info.IsDir()) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap("partials", err)
+	}
+
+	return filepath.WalkDir(b.partialsDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return errors.Wrap("partials", err)
+		}
+
+		// Partials are named after their files, so that errors during execution
+		// can be traced back to the right one.
+		if _, err := b.partials.New(file).Parse(string(contents)); err != nil {
+			return errors.TemplateParseError(err, file, string(contents), 0)
+		}
+
+		rel, _ := filepath.Rel(b.partialsDir, file)
+		name := strings.TrimSuffix(rel, path.Ext(rel))
+		b.partialFiles[name] = file
+		b.partialSrcs[file] = string(contents)
+		return nil
+	})
+}
+
+// Whether any of files are in the partials dir.
+func (b *Builder) partialsChanged(files []string) bool {
+	for _, file := range files {
+		if strings.HasPrefix(path.Clean(file), b.partialsDir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Creates the function that renders partials for a page. The partials are
+// cloned the first time that the function is called, so that they can be
+// bound to the page.
+func (b *Builder) partialFunc(page *Page) func(name string, data any) (string, error) {
+	var partials *template.Template
+
+	return func(name string, data any) (string, error) {
+		file, ok := b.partialFiles[name]
+
+		if !ok {
+			return "", fmt.Errorf(`no partial called "%s" in _partials`, name)
+		}
+
+		page.addDep(file)
+
+		if partials == nil {
+			t, err := b.partials.Clone()
+			if err != nil {
+				return "", err
+			}
+			partials = t.Funcs(b.templateFuncs(page))
+		}
+
+		var buf bytes.Buffer
+
+		if err := partials.ExecuteTemplate(&buf, file, data); err != nil {
+			return "", errors.TemplateExecError(err, file, b.partialSrcs[file], 0)
+		}
+
+		return buf.String(), nil
+	}
+}
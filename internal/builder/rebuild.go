@@ -0,0 +1,297 @@
+package builder
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// The parts of a page that other pages can see through functions like
+// "index" and "pagesWith". The page's summary, word count and reading time
+// come from its source, so listing pages need rebuilding when that changes.
+type pageMeta struct {
+	url  string
+	data map[string]any
+	date time.Time
+	src  string
+}
+
+// Rebuilds the site after the given files have changed. Only the pages that
+// depend on those files are rebuilt. If the changes can't be traced back to
+// specific pages (or the previous build didn't finish) then the whole site is
+// rebuilt instead.
+func (b *Builder) Rebuild(files []string) error {
+	if !b.built {
+		b.Reset()
+		return b.Build()
+	}
+
+	pages, ok := b.affectedPages(files)
+
+	if !ok {
+		b.Reset()
+		return b.Build()
+	}
+
+	// If this rebuild fails, then the next one needs to start from scratch.
+	b.built = false
+
+	ok, err := b.rebuildPages(pages, files)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		b.Reset()
+		return b.Build()
+	}
+
+	b.built = true
+	return nil
+}
+
+// Finds the pages that need to be rebuilt when files change. Returns false if
+// the changes require a full rebuild instead.
+func (b *Builder) affectedPages(files []string) ([]*Page, bool) {
+	dirty := map[*Page]bool{}
+
+	for _, file := range files {
+		file = path.Clean(file)
+		_, statErr := os.Stat(file)
+		exists := statErr == nil
+
+		// The config can change the way that every page is built, and any page
+		// could be using the data or i18n files.
+		if file == b.configFile || b.isDataFile(file) || b.isI18nFile(file) {
+			return nil, false
+		}
+
+		if strings.HasPrefix(file, b.PublicDir+"/") {
+			continue
+		}
+
+		found := false
+
+		for _, page := range b.pages {
+			if page.deps[file] {
+				dirty[page] = true
+				found = true
+			}
+
+			// Removing a page changes the results of "index" and "pagesWith" for
+			// other pages.
+			if page.inputPath == file && !exists {
+				return nil, false
+			}
+		}
+
+		// Files that were created (or that we didn't know about) could be new
+		// pages, or imported by islands, so we have to assume the worst. Files
+		// that no longer exist can't be depended upon, so they're safe to skip.
+		if !found && exists {
+			return nil, false
+		}
+	}
+
+	var pages []*Page
+
+	for _, page := range b.pages {
+		if dirty[page] {
+			pages = append(pages, page)
+		}
+	}
+
+	return pages, true
+}
+
+// Re-reads and renders pages that are affected by a change to files. Returns
+// false if the changes require a full rebuild instead.
+func (b *Builder) rebuildPages(pages []*Page, files []string) (bool, error) {
+	changed := map[string]bool{}
+	for _, file := range files {
+		changed[path.Clean(file)] = true
+	}
+
+	if changed[b.templateFile] {
+		if err := b.readTemplate(); err != nil {
+			return false, err
+		}
+	}
+
+	if b.partialsChanged(files) {
+		if err := b.readPartials(); err != nil {
+			return false, err
+		}
+	}
+
+	b.site.BuildTime = time.Now()
+
+	// Layouts and defaults are cheap to read again, and any of their files
+	// could have changed.
+	b.clearLayouts()
+	b.clearDefaults()
+
+	// Paginated and generated pages can change the number of pages they output,
+	// so it's simpler to start from scratch.
+	if hasGeneratedPages(pages) {
+		return false, nil
+	}
+
+	metas := map[*Page]pageMeta{}
+	for _, page := range pages {
+		metas[page] = pageMeta{page.Url, page.Data, page.Date, page.src}
+	}
+
+	// Pages that show these pages as their parents, children or siblings,
+	// before and after the change.
+	related := map[*Page]bool{}
+	for _, page := range pages {
+		for _, p := range b.relatedPages(page) {
+			related[p] = true
+		}
+	}
+
+	if err := b.readPages(pages); err != nil {
+		return false, err
+	}
+
+	// Pages that are no longer published need to be removed from the site.
+	for _, page := range pages {
+		if !b.isPublished(page) {
+			return false, nil
+		}
+	}
+
+	if err := b.assignUrls(); err != nil {
+		return false, err
+	}
+
+	// Pages that moved need their old output removed and their links updated.
+	for page, meta := range metas {
+		if page.Url != meta.url {
+			return false, nil
+		}
+	}
+
+	// Changing a page's terms can change which term pages need to exist, and
+	// changing its aliases can leave old redirects behind.
+	for page, meta := range metas {
+		keys := append([]string{"aliases"}, b.config.Taxonomies...)
+
+		for _, key := range keys {
+			if !reflect.DeepEqual(meta.data[key], page.Data[key]) {
+				return false, nil
+			}
+		}
+	}
+
+	// If any front matter or content changed, then pages that list other pages
+	// need to be rebuilt too.
+	metaChanged := false
+	for page, meta := range metas {
+		if !reflect.DeepEqual(meta.data, page.Data) || !meta.date.Equal(page.Date) || meta.src != page.src {
+			metaChanged = true
+			break
+		}
+	}
+
+	if metaChanged {
+		// We don't know which pages listed the whole site.
+		if b.site.isListed() {
+			return false, nil
+		}
+
+		b.linkPages()
+
+		for _, page := range pages {
+			for _, p := range b.relatedPages(page) {
+				related[p] = true
+			}
+		}
+
+		var listers []*Page
+
+		for _, page := range b.pages {
+			if _, ok := metas[page]; !ok && (page.listsPages || related[page]) {
+				listers = append(listers, page)
+			}
+		}
+
+		if hasGeneratedPages(listers) {
+			return false, nil
+		}
+
+		if err := b.readPages(listers); err != nil {
+			return false, err
+		}
+
+		pages = append(pages, listers...)
+	}
+
+	prevAssets := map[string]bool{}
+	for src := range b.assets {
+		prevAssets[src] = true
+	}
+
+	if err := b.updatePublicAssets(files); err != nil {
+		return false, err
+	}
+
+	if err := b.renderPages(pages); err != nil {
+		return false, err
+	}
+
+	if err := b.writeSiteFiles(); err != nil {
+		return false, err
+	}
+
+	// Copy the assets that changed, or that were referenced for the first time.
+	for src, url := range b.assets {
+		if changed[src] || !prevAssets[src] {
+			copyFile(src, path.Join(b.OutDir, url))
+		}
+	}
+
+	if err := b.checkLinks(pages); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func hasGeneratedPages(pages []*Page) bool {
+	for _, page := range pages {
+		if page.Paginator != nil || page.virtual {
+			return true
+		}
+	}
+	return false
+}
+
+// Keeps the asset map in sync with files that were added to, or removed from
+// the public dir.
+func (b *Builder) updatePublicAssets(files []string) error {
+	for _, file := range files {
+		file = path.Clean(file)
+
+		if !strings.HasPrefix(file, b.PublicDir+"/") {
+			continue
+		}
+
+		info, err := os.Stat(file)
+
+		if os.IsNotExist(err) {
+			delete(b.assets, file)
+		} else if err != nil {
+			return err
+		} else if !info.IsDir() {
+			rel, _ := filepath.Rel(b.PublicDir, file)
+			b.assets[file] = rel
+		}
+	}
+
+	return nil
+}
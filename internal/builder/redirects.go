@@ -0,0 +1,143 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// The files that redirects can be written to for hosts that support them.
+var redirectFormats = map[string]bool{
+	"_redirects":     true,
+	"redirects.json": true,
+}
+
+// The html written to each alias, which sends browsers (and search engines)
+// to the page's real url.
+const aliasTemplate = `<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8" />
+    <title>%[1]s</title>
+    <link rel="canonical" href="%[2]s" />
+    <meta name="robots" content="noindex" />
+    <meta http-equiv="refresh" content="0; url=%[1]s" />
+  </head>
+</html>
+`
+
+type jsonRedirects struct {
+	Redirects []jsonRedirect `json:"redirects"`
+}
+
+type jsonRedirect struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Permanent   bool   `json:"permanent"`
+}
+
+// Collects the old urls that pages list in their "aliases" front matter, so
+// that they can redirect to the pages. Aliases can't use the same url as
+// another page or alias.
+func (b *Builder) collectAliases(outputs map[string]*Page) error {
+	b.aliases = map[string]*Page{}
+
+	for _, page := range b.pages {
+		if page.virtual {
+			continue
+		}
+
+		for _, alias := range stringValues(page.Data["aliases"]) {
+			url := normalizeUrl(alias)
+			file := path.Join(b.OutDir, urlToFile(url))
+
+			if other, ok := outputs[file]; ok {
+				return frontMatterError(page, "aliases", fmt.Sprintf(`"%s" is already the url of "%s"`, url, other.Path))
+			}
+
+			if other, ok := b.aliases[url]; ok && other != page {
+				return frontMatterError(page, "aliases", fmt.Sprintf(`"%s" is already an alias of "%s"`, url, other.Path))
+			}
+
+			b.aliases[url] = page
+		}
+	}
+
+	return nil
+}
+
+// Writes a redirect page for each alias, and the host's redirects file if
+// the config asks for one.
+func (b *Builder) writeRedirects() error {
+	urls := []string{}
+
+	for url := range b.aliases {
+		urls = append(urls, url)
+	}
+
+	sort.Strings(urls)
+
+	for _, url := range urls {
+		page := b.aliases[url]
+		canonical := page.Url
+
+		if b.config.BaseUrl != "" {
+			canonical = b.absUrl(page.Url)
+		}
+
+		contents := fmt.Sprintf(aliasTemplate, html.EscapeString(page.Url), html.EscapeString(canonical))
+		file := path.Join(b.OutDir, urlToFile(url))
+
+		if err := os.MkdirAll(path.Dir(file), 0755); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(file, []byte(contents), 0644); err != nil {
+			return err
+		}
+	}
+
+	switch b.config.Redirects {
+	case "_redirects":
+		return b.writeNetlifyRedirects(urls)
+	case "redirects.json":
+		return b.writeJsonRedirects(urls)
+	}
+
+	return nil
+}
+
+// Writes redirects in the format that Netlify and Cloudflare Pages use.
+func (b *Builder) writeNetlifyRedirects(urls []string) error {
+	var sb strings.Builder
+
+	for _, url := range urls {
+		sb.WriteString(fmt.Sprintf("%s %s 301\n", url, b.aliases[url].Url))
+	}
+
+	return os.WriteFile(path.Join(b.OutDir, "_redirects"), []byte(sb.String()), 0644)
+}
+
+// Writes redirects in the format that Vercel uses.
+func (b *Builder) writeJsonRedirects(urls []string) error {
+	redirects := jsonRedirects{Redirects: []jsonRedirect{}}
+
+	for _, url := range urls {
+		redirects.Redirects = append(redirects.Redirects, jsonRedirect{
+			Source:      url,
+			Destination: b.aliases[url].Url,
+			Permanent:   true,
+		})
+	}
+
+	data, err := json.MarshalIndent(redirects, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path.Join(b.OutDir, "redirects.json"), append(data, '\n'), 0644)
+}
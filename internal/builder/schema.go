@@ -0,0 +1,125 @@
+package builder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema describes the front matter that the pages in a directory should
+// have.
+type Schema struct {
+	// The keys that pages can have, and what their values should look like.
+	Fields map[string]Field
+
+	// Whether pages are allowed to have keys that aren't in Fields.
+	Strict bool
+}
+
+// Field describes a single front matter key in a schema.
+type Field struct {
+	// One of the types in schemaTypes. Fields without a type can have any
+	// value.
+	Type string
+
+	// Whether every page needs to have the key.
+	Required bool
+}
+
+// Checks for each type of value that a field can have.
+var schemaTypes = map[string]func(b *Builder, value any) bool{
+	"any": func(b *Builder, value any) bool {
+		return true
+	},
+	"string": func(b *Builder, value any) bool {
+		_, ok := value.(string)
+		return ok
+	},
+	"number": func(b *Builder, value any) bool {
+		switch value.(type) {
+		case int, int64, uint64, float64:
+			return true
+		default:
+			return false
+		}
+	},
+	"bool": func(b *Builder, value any) bool {
+		_, ok := value.(bool)
+		return ok
+	},
+	"date": func(b *Builder, value any) bool {
+		_, ok := b.parseDate(value)
+		return ok
+	},
+	"list": func(b *Builder, value any) bool {
+		_, ok := value.([]any)
+		return ok
+	},
+	"map": func(b *Builder, value any) bool {
+		switch value.(type) {
+		case map[any]any, map[string]any:
+			return true
+		default:
+			return false
+		}
+	},
+}
+
+// Checks the page's front matter against the schema for its directory, if
+// there is one.
+func (b *Builder) validateFrontMatter(page *Page) error {
+	dir, ok := closestDir(page, mapKeys(b.config.Schemas))
+
+	if !ok {
+		return nil
+	}
+
+	schema := b.config.Schemas[dir]
+	names := mapKeys(schema.Fields)
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := schema.Fields[name]
+		value, ok := page.Data[name]
+
+		if !ok || value == nil {
+			if field.Required {
+				return frontMatterError(page, name, "is required")
+			}
+			continue
+		}
+
+		if check, ok := schemaTypes[field.Type]; ok && !check(b, value) {
+			expected := "a " + field.Type
+
+			if field.Type == "date" {
+				expected = b.expectedDate()
+			}
+
+			return frontMatterError(page, name, fmt.Sprintf("expected %s, got %v", expected, value))
+		}
+	}
+
+	if !schema.Strict {
+		return nil
+	}
+
+	keys := mapKeys(page.Data)
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if _, ok := schema.Fields[key]; !ok {
+			return frontMatterError(page, key, fmt.Sprintf("unknown key (expected one of %s)", strings.Join(names, ", ")))
+		}
+	}
+
+	return nil
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,111 @@
+package builder
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Site holds the values that are shared by every page, which templates can
+// access through .Site.
+type Site struct {
+	// The title of the site from the config.
+	Title string
+
+	// The url the site is deployed to from the config.
+	BaseUrl string
+
+	// Custom values from the "Params" key in the config.
+	Params map[string]any
+
+	// Values from the files in the _data dir, keyed by their names.
+	Data map[string]any
+
+	// The languages from the config. The first one is the default.
+	Languages []Language
+
+	// Either "development" or "production".
+	Mode string
+
+	// The time that the site was built.
+	BuildTime time.Time
+
+	pages []*Page
+
+	// Set to 1 when a template lists the site's pages, because those templates
+	// need rebuilding whenever any page changes.
+	listed int32
+}
+
+// All of the pages in the site, in the order they were found.
+func (s *Site) Pages() []*Page {
+	atomic.StoreInt32(&s.listed, 1)
+	return s.pages
+}
+
+// Groups the site's pages by the top level directory they're in. Pages at the
+// root of the site aren't in a section.
+func (s *Site) Sections() map[string][]*Page {
+	atomic.StoreInt32(&s.listed, 1)
+	sections := map[string][]*Page{}
+
+	for _, page := range s.pages {
+		dir := strings.TrimPrefix(page.Dir, "/")
+
+		if dir == "" {
+			continue
+		}
+
+		section := strings.Split(dir, "/")[0]
+		sections[section] = append(sections[section], page)
+	}
+
+	return sections
+}
+
+// Whether any template has listed the site's pages.
+func (s *Site) isListed() bool {
+	return atomic.LoadInt32(&s.listed) == 1
+}
+
+func (s *Site) markListed() {
+	atomic.StoreInt32(&s.listed, 1)
+}
+
+// Forgets whether the site's pages were listed, returning whether they were.
+func (s *Site) resetListed() bool {
+	return atomic.SwapInt32(&s.listed, 0) == 1
+}
+
+// Fills in the parts of the site that come from the config.
+func (b *Builder) applySiteConfig() {
+	b.site.Title = b.config.Title
+	b.site.BaseUrl = b.config.BaseUrl
+	b.site.Params = b.config.Params
+	b.site.Languages = b.config.Languages
+	b.site.Mode = b.Mode.String()
+	b.site.BuildTime = time.Now()
+
+	if b.site.Params == nil {
+		b.site.Params = map[string]any{}
+	}
+}
+
+// Gives the site the final list of pages, once generated pages have been
+// added and unpublished ones have been removed.
+func (b *Builder) collectSitePages() {
+	b.site.pages = []*Page{}
+
+	for _, page := range b.pages {
+		if page.isListable() && !page.isPlain() {
+			b.site.pages = append(b.site.pages, page)
+		}
+	}
+}
+
+func (m Mode) String() string {
+	if m == Production {
+		return "production"
+	}
+	return "development"
+}
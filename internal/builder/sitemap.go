@@ -0,0 +1,86 @@
+package builder
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+)
+
+type sitemapUrlSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	Urls    []sitemapUrl `xml:"url"`
+}
+
+type sitemapUrl struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Writes the files that describe the whole site, rather than individual pages.
+func (b *Builder) writeSiteFiles() error {
+	if err := b.writeFeeds(); err != nil {
+		return err
+	}
+
+	if err := b.writeRedirects(); err != nil {
+		return err
+	}
+
+	// Sitemaps need absolute urls, so they can't be generated without a BaseUrl.
+	if b.config.BaseUrl == "" {
+		return nil
+	}
+
+	if err := b.writeSitemap(); err != nil {
+		return err
+	}
+
+	return b.writeRobots()
+}
+
+// Writes a sitemap.xml that lists every page in the site, except for pages
+// that set "sitemap: false" in their front matter.
+func (b *Builder) writeSitemap() error {
+	urlSet := sitemapUrlSet{}
+
+	for _, page := range b.pages {
+		if page.Data["sitemap"] == false || !page.isHtml() {
+			continue
+		}
+
+		url := sitemapUrl{Loc: b.absUrl(page.Url)}
+		lastMod := page.Updated
+
+		if !lastMod.IsZero() {
+			url.LastMod = lastMod.Format("2006-01-02")
+		}
+
+		urlSet.Urls = append(urlSet.Urls, url)
+	}
+
+	sort.Slice(urlSet.Urls, func(i, j int) bool {
+		return urlSet.Urls[i].Loc < urlSet.Urls[j].Loc
+	})
+
+	data, err := marshalXml(urlSet)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path.Join(b.OutDir, "sitemap.xml"), data, 0644)
+}
+
+// Writes a robots.txt that points crawlers at the sitemap, unless the site
+// already has its own robots.txt in the public dir.
+func (b *Builder) writeRobots() error {
+	for _, url := range b.assets {
+		if path.Join("/", url) == "/robots.txt" {
+			return nil
+		}
+	}
+
+	robots := fmt.Sprintf("User-agent: *\nAllow: /\n\nSitemap: %s\n", b.absUrl("/sitemap.xml"))
+	return os.WriteFile(path.Join(b.OutDir, "robots.txt"), []byte(robots), 0644)
+}
@@ -0,0 +1,42 @@
+package builder
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/danprince/sietch/internal/errors"
+	"github.com/yuin/goldmark/parser"
+)
+
+// The number of words per minute that reading times are based on.
+const wordsPerMinute = 200
+
+var (
+	moreMarkerRegex = regexp.MustCompile(`<!--\s*more\s*-->`)
+	paragraphRegex  = regexp.MustCompile(`(?s)<p>.*?</p>`)
+	htmlTagRegex    = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Works out the summary, word count, and reading time for a page from its
+// body. The summary is the "summary" key from the page's front matter, or the
+// content before a <!--more--> marker, or the page's first paragraph.
+func (b *Builder) summarize(page *Page, pc parser.Context) error {
+	text := htmlTagRegex.ReplaceAllString(page.body, " ")
+	page.WordCount = len(strings.Fields(text))
+	page.ReadingTime = (page.WordCount + wordsPerMinute - 1) / wordsPerMinute
+
+	if summary, ok := page.Data["summary"].(string); ok {
+		var buf bytes.Buffer
+		if err := b.markdown.Convert([]byte(summary), &buf, parser.WithContext(pc)); err != nil {
+			return errors.Wrap("markdown", err)
+		}
+		page.Summary = strings.TrimSpace(buf.String())
+	} else if loc := moreMarkerRegex.FindStringIndex(page.body); loc != nil {
+		page.Summary = strings.TrimSpace(page.body[:loc[0]])
+	} else {
+		page.Summary = paragraphRegex.FindString(page.body)
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/danprince/sietch/internal/errors"
+)
+
+// Term is a value that pages are grouped by in a taxonomy (e.g. a tag).
+type Term struct {
+	Name     string
+	Slug     string
+	Taxonomy string
+	Pages    []*Page
+	page     *Page
+}
+
+// The url of the term's page.
+func (t *Term) Url() string {
+	return t.page.Url
+}
+
+// Maps each term's slug to the term.
+type taxonomy map[string]*Term
+
+// Returns the terms in the taxonomy, sorted by name.
+func (t taxonomy) terms() []*Term {
+	terms := []*Term{}
+	for _, term := range t {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		return strings.ToLower(terms[i].Name) < strings.ToLower(terms[j].Name)
+	})
+	return terms
+}
+
+// The default contents for a taxonomy's index page.
+const defaultTermsTemplate = `{{ range terms %q -}}
+- [{{ .Name }}]({{ .Url }}) ({{ len .Pages }})
+{{ end }}`
+
+// The default contents for a term's page.
+const defaultTermTemplate = `{{ range .Term.Pages -}}
+- [{{ .Data.title }}]({{ .Url }})
+{{ end }}`
+
+// Groups pages by the taxonomies from the config, and creates an index page
+// for each taxonomy (e.g. /tags/) and a page for each term (e.g. /tags/go/).
+// These pages can be customised with _terms.md and _term.md files in the
+// taxonomy's directory.
+func (b *Builder) collectTaxonomies() error {
+	for _, name := range b.config.Taxonomies {
+		tax := taxonomy{}
+		b.taxonomies[name] = tax
+
+		for _, page := range b.pages {
+			if !page.isListable() {
+				continue
+			}
+
+			for _, value := range stringValues(page.Data[name]) {
+				slug := slugify(value)
+				term := tax[slug]
+
+				if slug == "" {
+					return frontMatterError(page, name, fmt.Sprintf(`"%s" needs at least one letter or number to be used as a term`, value))
+				}
+
+				if term == nil {
+					term = &Term{Name: value, Slug: slug, Taxonomy: name}
+					tax[slug] = term
+				} else if !sameTerm(term.Name, value) {
+					return frontMatterError(page, name, fmt.Sprintf(`"%s" and "%s" would both be written to %s`, term.Name, value, path.Join("/", name, slug)+"/"))
+				}
+
+				// Avoid listing pages twice if they repeat a term
+				if n := len(term.Pages); n == 0 || term.Pages[n-1] != page {
+					term.Pages = append(term.Pages, page)
+				}
+			}
+		}
+
+		dir := path.Join("/", name)
+		termsTemplateFile := path.Join(b.PagesDir, dir, "_terms.md")
+		termTemplateFile := path.Join(b.PagesDir, dir, "_term.md")
+
+		// Sites can write their own index page for the taxonomy instead.
+		if b.findPage(path.Join(b.PagesDir, dir, "index.md")) == nil {
+			_, err := b.addGeneratedPage(path.Join(dir, "index.md"), termsTemplateFile, fmt.Sprintf(defaultTermsTemplate, name), name)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, term := range tax.terms() {
+			sortByDateDesc(term.Pages)
+
+			page, err := b.addGeneratedPage(path.Join(dir, term.Slug, "index.md"), termTemplateFile, defaultTermTemplate, term.Name)
+			if err != nil {
+				return err
+			}
+
+			page.Term = term
+			term.page = page
+		}
+	}
+
+	return nil
+}
+
+// Adds a page that doesn't have a markdown file of its own to the builder.
+// The page's contents are read from templateFile if it exists, otherwise
+// defaultContents is used instead.
+func (b *Builder) addGeneratedPage(relPath string, templateFile string, defaultContents string, title string) (*Page, error) {
+	contents, err := os.ReadFile(templateFile)
+
+	if os.IsNotExist(err) {
+		contents = []byte(defaultContents)
+	} else if err != nil {
+		return nil, errors.Wrap("builder", err)
+	}
+
+	page := &Page{
+		id:        shortHash(relPath),
+		Path:      relPath,
+		Dir:       path.Dir(relPath),
+		Lang:      b.defaultLang(),
+		Site:      b.site,
+		key:       relPath,
+		langDir:   path.Dir(relPath),
+		inputPath: templateFile,
+		virtual:   true,
+	}
+
+	if err := b.parsePage(page, contents); err != nil {
+		return nil, err
+	}
+
+	if page.Data["title"] == nil {
+		page.Data["title"] = title
+	}
+
+	page.listsPages = true
+	b.pages = append(b.pages, page)
+	return page, nil
+}
+
+// Finds the page that was read from file.
+func (b *Builder) findPage(file string) *Page {
+	for _, p := range b.pages {
+		if p.inputPath == file && !p.virtual {
+			return p
+		}
+	}
+	return nil
+}
+
+// Turns a front matter value that can be a single value or a list (e.g. the
+// terms in a taxonomy) into a list of strings.
+func stringValues(value any) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		names := []string{}
+		for _, item := range v {
+			names = append(names, fmt.Sprint(item))
+		}
+		return names
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+var nonSlugRegex = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// Turns a string into a lowercase, url-safe slug. Letters and numbers from
+// any script are kept.
+func slugify(s string) string {
+	s = strings.ToLower(s)
+	s = nonSlugRegex.ReplaceAllString(s, "-")
+	return strings.Trim(s, "-")
+}
+
+var termSeparatorRegex = regexp.MustCompile(`[\s_-]+`)
+
+// Whether two values are different ways of writing the same term, where only
+// their case or separators differ (e.g. "Static Sites" and "static-sites").
+func sameTerm(a string, b string) bool {
+	normalize := func(s string) string {
+		return termSeparatorRegex.ReplaceAllString(strings.ToLower(strings.TrimSpace(s)), "-")
+	}
+	return normalize(a) == normalize(b)
+}
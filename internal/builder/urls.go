@@ -0,0 +1,228 @@
+package builder
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/danprince/sietch/internal/errors"
+)
+
+var permalinkTokenRegex = regexp.MustCompile(`:(\w+)`)
+
+// Tokens that can be used in permalink patterns, and how to expand them for a
+// given page and slug.
+var permalinkTokens = map[string]func(page *Page, slug string) string{
+	"year":  func(p *Page, slug string) string { return p.Date.Format("2006") },
+	"month": func(p *Page, slug string) string { return p.Date.Format("01") },
+	"day":   func(p *Page, slug string) string { return p.Date.Format("02") },
+	"slug":  func(p *Page, slug string) string { return slug },
+}
+
+// Tokens that only make sense for pages with dates.
+var permalinkDateTokens = map[string]bool{
+	"year":  true,
+	"month": true,
+	"day":   true,
+}
+
+// Works out the urls and output paths for every page, based on their paths,
+// front matter, and the site's permalink patterns.
+func (b *Builder) assignUrls() error {
+	outputs := map[string]*Page{}
+
+	for _, page := range b.pages {
+		if page.source != nil {
+			page.Url = subpathUrl(page.source.Url, page.subpath)
+		} else {
+			url, err := b.pageUrl(page)
+			if err != nil {
+				return err
+			}
+			page.Url = url
+		}
+
+		page.outputPath = path.Join(b.OutDir, urlToFile(page.Url))
+
+		if other, ok := outputs[page.outputPath]; ok {
+			file := urlToFile(page.Url)
+			return errors.Wrap("urls", fmt.Errorf(`"%s" and "%s" are both written to "%s"`, other.Path, page.Path, file))
+		}
+
+		outputs[page.outputPath] = page
+	}
+
+	// Feeds are written next to the pages that ask for them.
+	for _, page := range b.pages {
+		if page.Data["feed"] != true || page.virtual {
+			continue
+		}
+
+		for _, name := range feedFiles {
+			url := path.Join(page.urlDir(), name)
+			file := path.Join(b.OutDir, urlToFile(url))
+
+			if other, ok := outputs[file]; ok {
+				return errors.Wrap("urls", fmt.Errorf(`"%s" and the feeds for "%s" are both written to "%s"`, other.Path, page.Path, urlToFile(url)))
+			}
+
+			outputs[file] = page
+		}
+	}
+
+	return b.collectAliases(outputs)
+}
+
+// Determines the url for a page. Pages can set their url directly with a
+// "url" key in their front matter, or change the last part of their url with
+// a "slug" key. Pages that aren't in the default language have their
+// language at the start of their url.
+func (b *Builder) pageUrl(page *Page) (string, error) {
+	if url, ok := page.Data["url"].(string); ok {
+		return normalizeUrl(url), nil
+	}
+
+	prefix := b.langPrefix(page.Lang)
+
+	name := path.Base(page.key)
+	dir := path.Dir(page.key)
+	isIndex := isIndexFile(name)
+
+	// The root index page is always the root of the site.
+	if isIndex && dir == "/" {
+		return normalizeUrl(prefix + "/"), nil
+	}
+
+	// Plain templates are written to exactly where their names say.
+	if page.isPlain() {
+		name = strings.TrimSuffix(name, templateExt)
+
+		if s, ok := page.Data["slug"].(string); ok {
+			name = s
+		}
+
+		return path.Join("/", prefix, dir, name), nil
+	}
+
+	slug := strings.TrimSuffix(name, path.Ext(name))
+
+	// Index pages take their slug from their directory instead.
+	if isIndex {
+		slug = path.Base(dir)
+		dir = path.Dir(dir)
+	}
+
+	if s, ok := page.Data["slug"].(string); ok {
+		slug = s
+	}
+
+	if pattern, ok := b.permalinkPattern(page); ok {
+		// Pages without dates can't use patterns with date tokens.
+		for _, m := range permalinkTokenRegex.FindAllStringSubmatch(pattern, -1) {
+			if permalinkDateTokens[m[1]] && page.Date.IsZero() {
+				return "", errors.Wrap("urls", fmt.Errorf(`"%s" doesn't have a date, but its permalink pattern "%s" uses ":%s"`, page.Path, pattern, m[1]))
+			}
+		}
+
+		return expandPermalink(prefix+"/"+pattern, page, slug), nil
+	}
+
+	if isIndex || b.config.PrettyUrls {
+		return path.Join(prefix, dir, slug) + "/", nil
+	}
+
+	return path.Join(prefix, dir, slug+".html"), nil
+}
+
+// Finds the most specific permalink pattern from the config that applies to
+// a page. Patterns don't apply to the index page of their own directory.
+func (b *Builder) permalinkPattern(page *Page) (string, bool) {
+	dir, ok := closestDir(page, mapKeys(b.config.Permalinks))
+	return b.config.Permalinks[dir], ok
+}
+
+// Finds the most specific directory in dirs (relative to the pages dir) that
+// a page is inside of. Index pages don't count as being inside their own
+// directory. Returns the directory as it was written in dirs.
+func closestDir(page *Page, dirs []string) (string, bool) {
+	match := ""
+	found := false
+	pageDir := path.Dir(page.key)
+
+	for _, d := range dirs {
+		dir := path.Join("/", d)
+
+		if isIndexFile(page.key) && pageDir == dir {
+			continue
+		}
+
+		inDir := dir == "/" || pageDir == dir || strings.HasPrefix(pageDir, dir+"/")
+
+		if inDir && (!found || len(dir) > len(path.Join("/", match))) {
+			match = d
+			found = true
+		}
+	}
+
+	return match, found
+}
+
+// Replaces the tokens in a permalink pattern with values from the page.
+func expandPermalink(pattern string, page *Page, slug string) string {
+	url := permalinkTokenRegex.ReplaceAllStringFunc(pattern, func(token string) string {
+		if expand, ok := permalinkTokens[token[1:]]; ok {
+			return expand(page, slug)
+		}
+		return token
+	})
+
+	return normalizeUrl(url)
+}
+
+// Makes sure that a url is absolute and clean. Urls without extensions are
+// treated as directories.
+func normalizeUrl(url string) string {
+	isDir := strings.HasSuffix(url, "/") || path.Ext(url) == ""
+	url = path.Join("/", url)
+
+	if isDir && url != "/" {
+		url += "/"
+	}
+
+	return url
+}
+
+// Converts a url into the path of the file that it will be served from,
+// relative to the output directory.
+func urlToFile(url string) string {
+	if strings.HasSuffix(url, "/") {
+		return url + "index.html"
+	}
+	return url
+}
+
+// The directory that relative urls in this page's output are resolved from.
+func (p *Page) urlDir() string {
+	if strings.HasSuffix(p.Url, "/") {
+		return p.Url
+	}
+	return path.Dir(p.Url)
+}
+
+// Finds the url relative to dir (which should be a url directory, like the
+// ones from urlDir).
+func relativeUrl(dir string, url string) string {
+	rel, err := filepath.Rel(dir, url)
+
+	if err != nil {
+		return url
+	}
+
+	if strings.HasSuffix(url, "/") {
+		rel += "/"
+	}
+
+	return rel
+}
@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"os"
@@ -21,6 +23,7 @@ var (
 	yamlLineErrorRegex      = regexp.MustCompile(`yaml: line (\d+): `)
 	templateParseErrorRegex = regexp.MustCompile(`template: .+:(\d+): `)
 	templateExecErrorRegex  = regexp.MustCompile(`template: .+:(\d+):(\d+): .+ <(.+?)>: `)
+	tomlLineErrorRegex      = regexp.MustCompile(`Near line (\d+) \(last key parsed '.*?'\): `)
 )
 
 const (
@@ -172,6 +175,16 @@ func EsbuildError(result api.BuildResult) error {
 }
 
 func YamlParseError(err error, file string, contents string) error {
+	return yamlError(err, file, contents, `Couldn't parse the front matter from this file.`)
+}
+
+// YamlDataError is a YamlParseError from a data file, rather than from a
+// page's front matter.
+func YamlDataError(err error, file string, contents string) error {
+	return yamlError(err, file, contents, `Couldn't parse the data from this file.`)
+}
+
+func yamlError(err error, file string, contents string, details string) error {
 	matches := yamlLineErrorRegex.FindStringSubmatch(err.Error())
 
 	if len(matches) < 2 {
@@ -186,7 +199,45 @@ func YamlParseError(err error, file string, contents string) error {
 		line:     line,
 		contents: contents,
 		message:  msg,
-		details:  `Couldn't parse the front matter from this file.`,
+		details:  details,
+	}
+}
+
+// FrontMatterError points at the line where key is defined in a file's front
+// matter. Keys can be top level YAML keys, or quoted JSON keys.
+func FrontMatterError(file string, contents string, key string, message string) error {
+	line := 1
+	keyRegex := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `\s*:`)
+	jsonKeyRegex := regexp.MustCompile(`(?m)^\s*"` + regexp.QuoteMeta(key) + `"\s*:`)
+
+	if match := keyRegex.FindStringIndex(contents); match != nil {
+		line, _ = loc(contents, match[0])
+	} else if match := jsonKeyRegex.FindStringIndex(contents); match != nil {
+		line, _ = loc(contents, match[0])
+	}
+
+	return &SourceError{
+		file:     file,
+		line:     line,
+		contents: contents,
+		message:  fmt.Sprintf("front matter: %s: %s", key, message),
+	}
+}
+
+// BrokenLinkError points at the first place that link appears in a file.
+func BrokenLinkError(file string, contents string, link string, message string) error {
+	line, column := 1, 0
+
+	if i := strings.Index(contents, link); i >= 0 {
+		line, column = loc(contents, i)
+	}
+
+	return &SourceError{
+		file:     file,
+		line:     line,
+		column:   column,
+		contents: contents,
+		message:  fmt.Sprintf("broken link: %s", message),
 	}
 }
 
@@ -210,6 +261,13 @@ func TemplateParseError(err error, file string, contents string, lineOffset int)
 }
 
 func TemplateExecError(err error, file string, contents string, lineOffset int) error {
+	// Errors from templates that were executed by this one (e.g. partials)
+	// already point at the right place.
+	var srcErr *SourceError
+	if errors.As(err, &srcErr) {
+		return srcErr
+	}
+
 	matches := templateExecErrorRegex.FindStringSubmatch(err.Error())
 
 	if len(matches) < 3 {
@@ -258,6 +316,38 @@ func JsonParseError(err error, file string, contents string) error {
 	return err
 }
 
+func TomlParseError(err error, file string, contents string) error {
+	matches := tomlLineErrorRegex.FindStringSubmatch(err.Error())
+
+	if len(matches) < 2 {
+		return err
+	}
+
+	line, _ := strconv.Atoi(matches[1])
+	msg := tomlLineErrorRegex.ReplaceAllString(err.Error(), "")
+
+	return &SourceError{
+		file:     file,
+		line:     line,
+		contents: contents,
+		message:  fmt.Sprintf("toml: %s", msg),
+	}
+}
+
+func CsvParseError(err error, file string, contents string) error {
+	if err, ok := err.(*csv.ParseError); ok {
+		return &SourceError{
+			file:     file,
+			line:     err.Line,
+			column:   err.Column,
+			contents: contents,
+			message:  fmt.Sprintf("csv: %s", err.Err),
+		}
+	}
+
+	return err
+}
+
 var (
 	v8LocationRegex   = regexp.MustCompile(`(.+):(\d+):(\d+)`)
 	v8StackFrameRegex = regexp.MustCompile(`at\s*(\S*)\s*\(?(.*?):(\d+):(\d+)\)`)
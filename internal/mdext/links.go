@@ -1,6 +1,8 @@
 package mdext
 
 import (
+	"net/url"
+	"path"
 	"strings"
 
 	"github.com/yuin/goldmark"
@@ -11,6 +13,7 @@ import (
 )
 
 type links struct {
+	prettyUrls bool
 }
 
 func (e *links) Extend(m goldmark.Markdown) {
@@ -21,11 +24,48 @@ func (e *links) Extend(m goldmark.Markdown) {
 
 // Adds the appropriate attributes for opening external links in a new tab
 // and without a referrer/opener.
-var Links = &links{}
+var Links = NewLinks(false)
+
+// Creates a links extension. If prettyUrls is true, then links to markdown
+// files are rewritten as directories (foo.md becomes foo/) instead of html
+// files.
+func NewLinks(prettyUrls bool) *links {
+	return &links{prettyUrls: prettyUrls}
+}
+
+var linkBaseKey = parser.NewContextKey()
+
+// Sets the path that relative links and images should be resolved from. This
+// is needed when a page isn't written to the same directory as its source
+// file (e.g. with pretty urls, foo.md is written to foo/index.html, so the
+// base would be "..").
+func SetLinkBase(pc parser.Context, base string) {
+	pc.Set(linkBaseKey, base)
+}
 
 func (t *links) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	base, _ := pc.Get(linkBaseKey).(string)
+	resolve, _ := pc.Get(linkResolverKey).(func(string) (string, bool))
+	isPageRelative, _ := pc.Get(pageRelativeKey).(func(string) bool)
+
+	rebase := func(src string) string {
+		if isPageRelative != nil && isPageRelative(src) {
+			return src
+		}
+		return rebase(base, src)
+	}
+
 	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		if !entering || n.Kind() != ast.KindLink {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		if image, ok := n.(*ast.Image); ok {
+			image.Destination = []byte(rebase(string(image.Destination)))
+			return ast.WalkContinue, nil
+		}
+
+		if n.Kind() != ast.KindLink {
 			return ast.WalkContinue, nil
 		}
 
@@ -37,12 +77,80 @@ func (t *links) Transform(node *ast.Document, reader text.Reader, pc parser.Cont
 			link.SetAttribute([]byte("rel"), []byte("noopener noreferrer"))
 		}
 
+		// Keep fragments and queries out of the way while rewriting the path.
+		suffix := ""
+		if i := strings.IndexAny(src, "?#"); i >= 0 {
+			src, suffix = src[:i], src[i:]
+		}
+
+		isLocal := isRelative(src) || strings.HasPrefix(src, "/")
+
+		if strings.HasSuffix(src, ".md") && isLocal && resolve != nil {
+			if url, ok := resolve(src); ok {
+				link.Destination = []byte(url + suffix)
+				return ast.WalkContinue, nil
+			}
+		}
+
 		if strings.HasSuffix(src, ".md") {
-			src = strings.Replace(src, ".md", ".html", 1)
-			src = strings.Replace(src, "index.html", "", 1)
-			link.Destination = []byte(src)
+			src = strings.TrimSuffix(src, ".md")
+
+			if path.Base(src) == "index" {
+				src = strings.TrimSuffix(src, "index")
+			} else if t.prettyUrls {
+				src += "/"
+			} else {
+				src += ".html"
+			}
+
+			if src == "" {
+				src = "./"
+			}
 		}
 
+		link.Destination = []byte(rebase(src) + suffix)
 		return ast.WalkContinue, nil
 	})
 }
+
+var linkResolverKey = parser.NewContextKey()
+
+// Sets a function that decides where links to markdown files should point.
+// The function is called with the link's destination (without its fragment)
+// and if it returns false, the link is rewritten as usual.
+func SetLinkResolver(pc parser.Context, resolve func(dest string) (string, bool)) {
+	pc.Set(linkResolverKey, resolve)
+}
+
+var pageRelativeKey = parser.NewContextKey()
+
+// Sets a function that reports whether a link or image destination is already
+// relative to the page's url rather than its source file (e.g. because a
+// template function produced it), so that it isn't resolved from the base.
+func SetPageRelative(pc parser.Context, isPageRelative func(dest string) bool) {
+	pc.Set(pageRelativeKey, isPageRelative)
+}
+
+// Prepends base to relative urls.
+func rebase(base string, src string) string {
+	if base == "" || base == "." || !isRelative(src) {
+		return src
+	}
+
+	dst := path.Join(base, src)
+
+	if strings.HasSuffix(src, "/") || src == "." {
+		dst += "/"
+	}
+
+	return dst
+}
+
+func isRelative(src string) bool {
+	if src == "" || strings.HasPrefix(src, "/") || strings.HasPrefix(src, "#") {
+		return false
+	}
+
+	u, err := url.Parse(src)
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
 )
 
 func TestExternalLinks(t *testing.T) {
@@ -38,3 +39,113 @@ func TestExternalLinks(t *testing.T) {
 		}
 	}
 }
+
+func TestMarkdownLinks(t *testing.T) {
+	type test struct {
+		input      string
+		output     string
+		prettyUrls bool
+		base       string
+	}
+
+	tests := []test{
+		{input: `[a](./a.md)`, output: `<a href="./a.html">a</a>`},
+		{input: `[b](./b/index.md)`, output: `<a href="./b/">b</a>`},
+		{input: `[a](./a.md)`, output: `<a href="./a/">a</a>`, prettyUrls: true},
+		{input: `[b](./b/index.md)`, output: `<a href="./b/">b</a>`, prettyUrls: true},
+		{input: `[a](./a.md)`, output: `<a href="../a/">a</a>`, prettyUrls: true, base: ".."},
+		{input: `[a](/a.md)`, output: `<a href="/a/">a</a>`, prettyUrls: true, base: ".."},
+		{input: `[x](#x)`, output: `<a href="#x">x</a>`, base: ".."},
+		{input: `[a](./a.md#x)`, output: `<a href="./a.html#x">a</a>`},
+		{input: `[b](./b/index.md?y#x)`, output: `<a href="../b/?y#x">b</a>`, base: ".."},
+		{input: `![i](i.png)`, output: `<img src="../i.png" alt="i">`, base: ".."},
+		{input: `[ext](https://ext.com)`, output: `<a href="https://ext.com" target="_blank" rel="noopener noreferrer">ext</a>`, base: ".."},
+	}
+
+	for _, tc := range tests {
+		md := goldmark.New(goldmark.WithExtensions(NewLinks(tc.prettyUrls)))
+		pc := parser.NewContext()
+		SetLinkBase(pc, tc.base)
+
+		var buf bytes.Buffer
+		err := md.Convert([]byte(tc.input), &buf, parser.WithContext(pc))
+		actual := strings.TrimSpace(buf.String())
+		expected := fmt.Sprintf(`<p>%s</p>`, tc.output)
+
+		if err != nil {
+			t.Errorf("unexpected markdown error: %s", err)
+		}
+
+		if actual != expected {
+			t.Errorf(`expected "%s", got "%s"`, expected, actual)
+		}
+	}
+}
+
+func TestLinkResolver(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(Links))
+
+	resolve := func(dest string) (string, bool) {
+		if dest == "a.md" {
+			return "/de/a.html", true
+		}
+		return "", false
+	}
+
+	tests := map[string]string{
+		`[a](a.md)`:   `<a href="/de/a.html">a</a>`,
+		`[a](a.md#x)`: `<a href="/de/a.html#x">a</a>`,
+		`[b](b.md)`:   `<a href="b.html">b</a>`,
+	}
+
+	for input, expected := range tests {
+		pc := parser.NewContext()
+		SetLinkResolver(pc, resolve)
+
+		var buf bytes.Buffer
+		err := md.Convert([]byte(input), &buf, parser.WithContext(pc))
+		actual := strings.TrimSpace(buf.String())
+		expected = fmt.Sprintf(`<p>%s</p>`, expected)
+
+		if err != nil {
+			t.Errorf("unexpected markdown error: %s", err)
+		}
+
+		if actual != expected {
+			t.Errorf(`expected "%s", got "%s"`, expected, actual)
+		}
+	}
+}
+
+func TestPageRelativeLinks(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(NewLinks(true)))
+
+	isPageRelative := func(dest string) bool {
+		return dest == "../h.txt"
+	}
+
+	tests := map[string]string{
+		`[h](../h.txt)`:  `<a href="../h.txt">h</a>`,
+		`![h](../h.txt)`: `<img src="../h.txt" alt="h">`,
+		`[i](i.txt)`:     `<a href="../i.txt">i</a>`,
+	}
+
+	for input, expected := range tests {
+		pc := parser.NewContext()
+		SetLinkBase(pc, "..")
+		SetPageRelative(pc, isPageRelative)
+
+		var buf bytes.Buffer
+		err := md.Convert([]byte(input), &buf, parser.WithContext(pc))
+		actual := strings.TrimSpace(buf.String())
+		expected = fmt.Sprintf(`<p>%s</p>`, expected)
+
+		if err != nil {
+			t.Errorf("unexpected markdown error: %s", err)
+		}
+
+		if actual != expected {
+			t.Errorf(`expected "%s", got "%s"`, expected, actual)
+		}
+	}
+}
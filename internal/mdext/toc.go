@@ -0,0 +1,125 @@
+package mdext
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Heading is an entry in a table of contents.
+type Heading struct {
+	Level    int
+	Text     string
+	Id       string
+	Children []*Heading
+}
+
+type toc struct {
+}
+
+func (e *toc) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(e, 100),
+	))
+}
+
+// Collects the document's headings into a table of contents, which can be
+// read from the parser context with GetToc after converting.
+var Toc = &toc{}
+
+var tocKey = parser.NewContextKey()
+
+// Returns the headings that were collected whilst parsing a document, nested
+// by their levels.
+func GetToc(pc parser.Context) []*Heading {
+	if headings, ok := pc.Get(tocKey).([]*Heading); ok {
+		return headings
+	}
+	return []*Heading{}
+}
+
+func (t *toc) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	headings := []*Heading{}
+	stack := []*Heading{}
+	source := reader.Source()
+
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Kind() != ast.KindHeading {
+			return ast.WalkContinue, nil
+		}
+
+		id, _ := n.AttributeString("id")
+		idBytes, _ := id.([]byte)
+
+		heading := &Heading{
+			Level: n.(*ast.Heading).Level,
+			Text:  string(n.Text(source)),
+			Id:    string(idBytes),
+		}
+
+		// Find the closest heading above this one with a lower level.
+		for len(stack) > 0 && stack[len(stack)-1].Level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			headings = append(headings, heading)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, heading)
+		}
+
+		stack = append(stack, heading)
+		return ast.WalkSkipChildren, nil
+	})
+
+	pc.Set(tocKey, headings)
+}
+
+// Renders a table of contents as nested lists of links. Headings outside of
+// the min and max levels are left out, but their children are still included.
+func RenderToc(headings []*Heading, min int, max int) string {
+	var sb strings.Builder
+	writeTocList(&sb, filterToc(headings, min, max))
+	return sb.String()
+}
+
+func filterToc(headings []*Heading, min int, max int) []*Heading {
+	filtered := []*Heading{}
+
+	for _, h := range headings {
+		children := filterToc(h.Children, min, max)
+
+		if h.Level < min || h.Level > max {
+			filtered = append(filtered, children...)
+		} else {
+			heading := *h
+			heading.Children = children
+			filtered = append(filtered, &heading)
+		}
+	}
+
+	return filtered
+}
+
+func writeTocList(sb *strings.Builder, headings []*Heading) {
+	if len(headings) == 0 {
+		return
+	}
+
+	sb.WriteString("<ul>")
+
+	for _, h := range headings {
+		sb.WriteString(fmt.Sprintf(`<li><a href="#%s">%s</a>`, h.Id, html.EscapeString(h.Text)))
+		writeTocList(sb, h.Children)
+		sb.WriteString("</li>")
+	}
+
+	sb.WriteString("</ul>")
+}
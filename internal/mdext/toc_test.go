@@ -0,0 +1,43 @@
+package mdext
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+)
+
+func TestToc(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(HeadingAnchors, Toc))
+
+	source := "# Title\n## One\n### One A\n## Two\n#### Deep\n## `Three` & *more*"
+
+	tests := []struct {
+		min    int
+		max    int
+		output string
+	}{
+		{1, 6, `<ul><li><a href="#title">Title</a><ul><li><a href="#one">One</a><ul><li><a href="#one-a">One A</a></li></ul></li><li><a href="#two">Two</a><ul><li><a href="#deep">Deep</a></li></ul></li><li><a href="#three--more">Three &amp; more</a></li></ul></li></ul>`},
+		{2, 2, `<ul><li><a href="#one">One</a></li><li><a href="#two">Two</a></li><li><a href="#three--more">Three &amp; more</a></li></ul>`},
+		{3, 6, `<ul><li><a href="#one-a">One A</a></li><li><a href="#deep">Deep</a></li></ul>`},
+		{5, 6, ``},
+	}
+
+	pc := parser.NewContext()
+	var buf bytes.Buffer
+
+	if err := md.Convert([]byte(source), &buf, parser.WithContext(pc)); err != nil {
+		t.Fatal(err)
+	}
+
+	headings := GetToc(pc)
+
+	for _, test := range tests {
+		actual := RenderToc(headings, test.min, test.max)
+
+		if actual != test.output {
+			t.Errorf("levels %d-%d: expected\n\"%s\",\n\"%s\"", test.min, test.max, test.output, actual)
+		}
+	}
+}
@@ -11,7 +11,9 @@ import (
 
 func main() {
 	var shouldServe bool
+	var future bool
 	flag.BoolVar(&shouldServe, "serve", false, "Serve & rebuild the site")
+	flag.BoolVar(&future, "future", false, "Publish pages with dates in the future")
 	flag.Parse()
 
 	rootDir, _ := os.Getwd()
@@ -23,6 +25,7 @@ func main() {
 	}
 
 	b := builder.New(rootDir, mode)
+	b.Future = future
 
 	// Start from a fresh slate each time the command is run
 	os.RemoveAll(b.OutDir)
@@ -37,6 +40,10 @@ func main() {
 	err := b.Build()
 	duration := time.Since(start)
 
+	for _, warning := range b.Warnings() {
+		fmt.Println(warning)
+	}
+
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/danprince/sietch/internal/builder"
@@ -15,7 +19,7 @@ func serve(b *builder.Builder) {
 	var buildErr error
 
 	lr := livereload.New()
-	server := http.FileServer(http.Dir(b.OutDir))
+	server := cleanUrls(b.OutDir, http.FileServer(http.Dir(b.OutDir)))
 
 	http.Handle("/ws", lr)
 
@@ -33,19 +37,26 @@ func serve(b *builder.Builder) {
 	watcher := watch(b.PagesDir, []string{b.OutDir})
 
 	go func() {
+		var changes []string
+
 		for {
 			fmt.Printf("\x1bc") // clear
 			start := time.Now()
-			buildErr = b.Build()
+			buildErr = b.Rebuild(changes)
 			duration := time.Since(start)
+			for _, warning := range b.Warnings() {
+				fmt.Println(warning)
+			}
 			if buildErr != nil {
 				fmt.Println(buildErr)
 			} else {
 				fmt.Printf("built site (%s)\n", duration)
 			}
 			lr.Notify()
-			<-watcher
-			b.Reset()
+			changes = nil
+			for _, event := range <-watcher {
+				changes = append(changes, event.Name)
+			}
 		}
 	}()
 
@@ -56,3 +67,20 @@ func serve(b *builder.Builder) {
 		log.Fatal(err)
 	}
 }
+
+// Resolves extensionless urls (e.g. /foo) to html files (/foo.html) the same
+// way that hosts with clean urls do. Directories are left to the file server,
+// which redirects /foo to /foo/ so that relative links keep working.
+func cleanUrls(dir string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := path.Clean(r.URL.Path)
+
+		if path.Ext(p) == "" && !strings.HasSuffix(r.URL.Path, "/") {
+			if info, err := os.Stat(filepath.Join(dir, p+".html")); err == nil && !info.IsDir() {
+				r.URL.Path = p + ".html"
+			}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}